@@ -1,7 +1,12 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"slices"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/state"
 )
 
 func runAttach(args []string) error {
@@ -12,18 +17,64 @@ func runAttach(args []string) error {
 
 	name := args[0]
 	dir := ""
+	tmplName := ""
 
 	for i := 1; i < len(args); i++ {
-		if args[i] == "--dir" && i+1 < len(args) {
+		switch {
+		case args[i] == "--dir" && i+1 < len(args):
 			dir = args[i+1]
-			break
+			i++
+		case args[i] == "--template" && i+1 < len(args):
+			tmplName = args[i+1]
+			i++
 		}
 	}
+	return attach(b, name, dir, tmplName)
+}
 
+// attach attaches to session name of b in dir, creating it (laid out as
+// template tmplName, when given) if it doesn't exist.
+func attach(b backend.Backend, name, dir, tmplName string) error {
 	if dir != "" {
 		if err := os.Chdir(dir); err != nil {
 			return err
 		}
 	}
+	if tmplName != "" {
+		cwd, _ := os.Getwd()
+		tb, cmd, err := templateCommand(b, name, cwd, tmplName)
+		if err != nil {
+			return err
+		}
+		state.Touch(state.Key(tb.Name(), "", name), !sessionExists(b, name))
+		return backend.ExecCommand("/bin/sh", []string{"sh", "-c", cmd})
+	}
+	state.Touch(state.Key(backend.Owner(b, name).Name(), "", name), !sessionExists(b, name))
 	return b.Attach(name)
 }
+
+// sessionExists reports whether b lists a session called name.
+func sessionExists(b backend.Backend, name string) bool {
+	sessions, err := b.FastList()
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(sessions, func(s backend.Session) bool { return s.Name == name })
+}
+
+// templateCommand loads the named template and returns the backend behind b
+// that supports templates, with the shell command that builds (or attaches)
+// session name with it there.
+func templateCommand(b backend.Backend, name, dir, tmplName string) (backend.Backend, string, error) {
+	tb, ok := backend.Provider[backend.Templater](b)
+	if !ok {
+		return nil, "", fmt.Errorf("%s doesn't support templates", b.Name())
+	}
+	templater, _ := backend.As[backend.Templater](tb)
+	tmpl, err := backend.LoadTemplate(tmplName)
+	if err != nil {
+		return nil, "", err
+	}
+	cmd, err := templater.TemplateCommand(name, dir, tmpl)
+	return tb, cmd, err
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/backend/fake"
+)
+
+// templatingFake is a fake backend that supports templates under its own name.
+type templatingFake struct{ *fake.Fake }
+
+func (f templatingFake) Name() string { return "tmux" }
+
+func (f templatingFake) TemplateCommand(name, dir string, t *backend.Template) (string, error) {
+	return "build " + name + " in " + dir, nil
+}
+
+func TestTemplateCommandAggregate(t *testing.T) {
+	config := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", config)
+	dir := filepath.Join(config, "zpick", "templates")
+	os.MkdirAll(dir, 0o755)
+	os.WriteFile(filepath.Join(dir, "dev.json"), []byte(`{"windows": [{"name": "editor"}]}`), 0o644)
+
+	// The primary member has no templates; the second one does.
+	primary := fake.NewAt(filepath.Join(config, "primary.json"))
+	tmux := templatingFake{fake.NewAt(filepath.Join(config, "tmux.json"))}
+	b := backend.NewMulti([]backend.Backend{primary, tmux})
+
+	tb, cmd, err := templateCommand(b, "api", "/src/api", "dev")
+	if err != nil {
+		t.Fatalf("templateCommand in the aggregate: %v", err)
+	}
+	if tb.Name() != "tmux" {
+		t.Errorf("template backend = %s, want tmux", tb.Name())
+	}
+	if cmd != "build api in /src/api" {
+		t.Errorf("command = %q", cmd)
+	}
+
+	if _, _, err := templateCommand(primary, "api", "/src/api", "dev"); err == nil || !strings.Contains(err.Error(), "doesn't support templates") {
+		t.Errorf("templateCommand without a templater = %v", err)
+	}
+}
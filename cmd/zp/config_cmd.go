@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/config"
+)
+
+func runConfig(args []string) error {
+	if len(args) == 0 {
+		return printConfig()
+	}
+
+	switch args[0] {
+	case "get":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: zp config get <section.key>")
+		}
+		value, err := config.Get(args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	case "set":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: zp config set <section.key> <value>")
+		}
+		return setConfig(args[1], strings.Join(args[2:], " "))
+	case "edit":
+		return editConfig()
+	case "path":
+		fmt.Println(config.Path())
+		return nil
+	default:
+		return fmt.Errorf("unknown config command %q (valid: get, set, edit, path)", args[0])
+	}
+}
+
+// printConfig lists every setting as section.key = value.
+func printConfig() error {
+	if _, err := config.Load(); err != nil {
+		return err
+	}
+	fmt.Printf("# %s\n", config.Path())
+	for _, key := range config.Keys() {
+		value, err := config.Get(key)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s = %s\n", key, value)
+	}
+	return nil
+}
+
+// setConfig routes settings that need backend validation through the backend
+// package; everything else goes straight to the config file.
+func setConfig(key, value string) error {
+	switch key {
+	case "backend.name":
+		return backend.SetBackend(value)
+	case "keys.mode":
+		return backend.SetKeyMode(value)
+	}
+	return config.Set(key, value)
+}
+
+// editConfig opens config.toml in $VISUAL or $EDITOR, creating it first so
+// the editor shows every section with its current value. An invalid config
+// still opens, so it can be fixed.
+func editConfig() error {
+	if _, err := os.Stat(config.Path()); os.IsNotExist(err) {
+		c, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if err := config.Save(c); err != nil {
+			return err
+		}
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", config.Path())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor: %w", err)
+	}
+
+	if _, err := config.Load(); err != nil {
+		return fmt.Errorf("config saved but invalid: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,490 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/autorun"
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/backend/fake"
+)
+
+// e2eEnv builds zp and returns the binary, a clean environment selecting the
+// fake backend, and the fake backend's state (seeded with sessions).
+func e2eEnv(t *testing.T, sessions ...string) (string, []string, *fake.Fake) {
+	t.Helper()
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "zp")
+	build := exec.Command("go", "build", "-o", bin, "./")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("build failed: %v\n%s", err, out)
+	}
+
+	statePath := filepath.Join(dir, "fake.json")
+	f := fake.NewAt(statePath)
+	st := &fake.State{}
+	for _, name := range sessions {
+		st.Sessions = append(st.Sessions, backend.Session{Name: name, StartedIn: "/tmp"})
+	}
+	if err := f.Save(st); err != nil {
+		t.Fatal(err)
+	}
+
+	env := []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + dir,
+		"XDG_CONFIG_HOME=" + filepath.Join(dir, "config"),
+		"TERM=xterm",
+		backend.BackendEnvVar + "=fake",
+		fake.StateEnvVar + "=" + statePath,
+	}
+	return bin, env, f
+}
+
+func loadState(t *testing.T, f *fake.Fake) *fake.State {
+	t.Helper()
+	st, err := f.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return st
+}
+
+func TestE2EPickerAttach(t *testing.T) {
+	bin, env, _ := e2eEnv(t, "alpha", "beta")
+
+	p := startPTY(t, bin, env)
+	p.expect("skip")
+	p.send("2")
+
+	if got, want := p.wait(), `ZPICK_SESSION='beta' : fake attach 'beta'`; got != want {
+		t.Errorf("eval'd command = %q, want %q", got, want)
+	}
+}
+
+func TestE2EPickerKeysStableAfterKill(t *testing.T) {
+	bin, env, _ := e2eEnv(t, "alpha", "beta", "gamma")
+	env = append(env, "ZPICK_NO_CONFIRM=1")
+
+	p := startPTY(t, bin, env)
+	p.expect("skip")
+	p.send("k")
+	p.expect("which session")
+	p.send("2")
+	p.expect("killed")
+	p.expect("skip")
+	p.send("\x1b")
+	p.wait()
+
+	// gamma keeps key 3 in the next picker, with 2 left free.
+	p = startPTY(t, bin, env)
+	p.expect("skip")
+	p.send("3")
+	if got, want := p.wait(), `ZPICK_SESSION='gamma' : fake attach 'gamma'`; got != want {
+		t.Errorf("eval'd command = %q, want %q", got, want)
+	}
+}
+
+func TestE2EPickerCursor(t *testing.T) {
+	bin, env, _ := e2eEnv(t, "alpha", "beta", "gamma")
+
+	p := startPTY(t, bin, env)
+	p.expect("skip")
+	p.send("\x1b[B")
+	p.expect("clear")
+	p.send("J")
+	p.expect("clear")
+	p.send("\r")
+
+	if got, want := p.wait(), `ZPICK_SESSION='beta' : fake attach 'beta'`; got != want {
+		t.Errorf("eval'd command = %q, want %q", got, want)
+	}
+}
+
+func TestE2EPickerPagedKill(t *testing.T) {
+	var names []string
+	for i := 1; i <= 40; i++ {
+		names = append(names, fmt.Sprintf("s%02d", i))
+	}
+	bin, env, f := e2eEnv(t, names...)
+	env = append(env, "ZPICK_NO_CONFIRM=1")
+
+	p := startPTY(t, bin, env)
+	p.expect("page 1/2")
+	p.send(">")
+	p.expect("page 2/2")
+	p.send("k")
+	p.expect("which session")
+	p.send("3") // keys restart on each page: 3 is s35
+	p.expect("killed")
+	p.expect("page 2/2")
+	p.send("\x1b")
+	p.wait()
+
+	st := loadState(t, f)
+	if len(st.Sessions) != 39 || slices.ContainsFunc(st.Sessions, func(s backend.Session) bool { return s.Name == "s35" }) {
+		t.Errorf("kill on page 2 should remove s35, killed %v", st.Killed)
+	}
+}
+
+func TestE2EPickerMarkAndKill(t *testing.T) {
+	bin, env, f := e2eEnv(t, "alpha", "beta", "gamma", "delta")
+	env = append(env, "ZPICK_NO_CONFIRM=1")
+
+	p := startPTY(t, bin, env)
+	p.expect("skip")
+	p.send("M")
+	p.expect("0 marked")
+	p.send("1")
+	p.expect("1 marked")
+	p.send("3")
+	p.expect("2 marked")
+	p.send("k")
+	p.expect("killed")
+	p.expect("skip")
+	p.send("\x1b")
+	p.wait()
+
+	st := loadState(t, f)
+	if !slices.Equal(st.Killed, []string{"alpha", "gamma"}) {
+		t.Errorf("killed = %v, want the two marked sessions", st.Killed)
+	}
+}
+
+func TestE2EPickerRedrawsOnResize(t *testing.T) {
+	var names []string
+	for i := 1; i <= 12; i++ {
+		names = append(names, fmt.Sprintf("s%02d", i))
+	}
+	bin, env, _ := e2eEnv(t, names...)
+
+	p := startPTY(t, bin, env)
+	p.expect("skip")
+	p.expectRaw()
+	// 18 rows can't hold all 12 sessions under the wrapped footer, so they
+	// go on pages; the redraw happens without a keypress.
+	p.resize(18, 40)
+	p.expect("page 1/")
+	p.send("k")
+	p.expect("which session")
+	p.expectRaw()
+	p.resize(40, 80)
+	p.expect("which session") // kill prompt redrawn on the single page
+	p.send("1")
+	p.expect("y/n")
+	p.expectRaw()
+	p.resize(30, 80)
+	p.expect("y/n") // and so is the confirmation
+	p.send("n")
+	p.expect("cancelled")
+	p.expect("skip")
+	p.send("R")
+	p.expect("which session")
+	p.expectRaw()
+	p.resize(40, 80)
+	p.expect("which session")
+	p.send("\x1b")
+	p.expect("skip")
+	p.send("\x1b")
+
+	if got := p.wait(); got != "" {
+		t.Errorf("escape should eval nothing, got %q", got)
+	}
+}
+
+func TestE2EPickerKill(t *testing.T) {
+	bin, env, f := e2eEnv(t, "alpha", "beta")
+	env = append(env, "ZPICK_NO_CONFIRM=1")
+
+	p := startPTY(t, bin, env)
+	p.expect("skip")
+	p.send("k")
+	p.expect("which session")
+	p.send("1")
+	p.expect("killed")
+	p.expect("skip")
+	p.send("\x1b")
+
+	if got := p.wait(); got != "" {
+		t.Errorf("escape should eval nothing, got %q", got)
+	}
+	st := loadState(t, f)
+	if len(st.Sessions) != 1 || st.Sessions[0].Name != "beta" {
+		t.Errorf("sessions after kill = %+v", st.Sessions)
+	}
+	if !slices.Equal(st.Killed, []string{"alpha"}) {
+		t.Errorf("killed = %v", st.Killed)
+	}
+}
+
+func TestE2EInSessionSwitchAndResume(t *testing.T) {
+	bin, env, _ := e2eEnv(t, "alpha", "beta")
+
+	p := startPTY(t, bin, append(env, fake.SessionEnvVar+"=alpha"))
+	p.expect("in: alpha")
+	p.send("2")
+	if got := p.wait(); got != ": fake detach" {
+		t.Fatalf("in-session pick should detach, got %q", got)
+	}
+
+	// The shell hook runs "zp resume" in the shell left behind by the detach.
+	resume := exec.Command(bin, "resume")
+	resume.Env = env
+	out, err := resume.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(out), `ZPICK_SESSION='beta' : fake attach 'beta'`; got != want {
+		t.Errorf("resume = %q, want %q", got, want)
+	}
+
+	out, err = resume.Output()
+	if err == nil && len(out) > 0 {
+		t.Errorf("second resume should be a no-op, got %q", out)
+	}
+}
+
+func TestE2EGuardAutorun(t *testing.T) {
+	bin, env, _ := e2eEnv(t, "alpha")
+
+	p := startPTY(t, bin, env, "guard", "--", "claude", "--model", "opus")
+	p.expect("ENTER")
+	p.expectRaw()
+	p.send("\r")
+	// The guard prompt also says "skip", so wait for the picker's own row
+	// and for it to be reading keys before picking.
+	p.expect("alpha")
+	p.expect("skip")
+	p.expectRaw()
+	p.send("1")
+
+	got := p.wait()
+	value, rest, ok := strings.Cut(strings.TrimPrefix(got, autorun.EnvVar+"="), " ")
+	if !ok || !strings.HasPrefix(got, autorun.EnvVar+"=") {
+		t.Fatalf("guard output = %q, want %s prefix", got, autorun.EnvVar)
+	}
+	if want := `ZPICK_SESSION='alpha' : fake attach 'alpha'`; rest != want {
+		t.Errorf("attach command = %q, want %q", rest, want)
+	}
+	argv, err := autorun.Decode(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(argv, []string{"claude", "--model", "opus"}) {
+		t.Errorf("autorun argv = %q", argv)
+	}
+}
+
+func TestE2EAttachCommandRecords(t *testing.T) {
+	bin, env, f := e2eEnv(t)
+
+	cmd := exec.Command(bin, "attach", "gamma")
+	cmd.Env = env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("zp attach: %v\n%s", err, out)
+	}
+
+	st := loadState(t, f)
+	if !slices.Equal(st.Attached, []string{"gamma"}) {
+		t.Errorf("attached = %v", st.Attached)
+	}
+	if len(st.Sessions) != 1 || st.Sessions[0].Name != "gamma" {
+		t.Errorf("sessions = %+v", st.Sessions)
+	}
+}
+
+func TestE2EWorktreeCommand(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	bin, env, f := e2eEnv(t)
+	repo := filepath.Join(t.TempDir(), "api")
+	os.MkdirAll(repo, 0o755)
+	os.WriteFile(filepath.Join(repo, "README"), []byte("api\n"), 0o644)
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"add", "README"},
+		{"-c", "user.name=zp", "-c", "user.email=zp@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	// The first run adds the worktree, the second reuses it.
+	for range 2 {
+		cmd := exec.Command(bin, "worktree", "feature/login")
+		cmd.Env, cmd.Dir = env, repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("zp worktree: %v\n%s", err, out)
+		}
+	}
+
+	// A branch that looks like an option is refused, not passed to git.
+	cmd := exec.Command(bin, "worktree", "--detach")
+	cmd.Env, cmd.Dir = env, repo
+	if out, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(out), "not a valid branch name") {
+		t.Errorf("zp worktree --detach: %v\n%s", err, out)
+	}
+
+	st := loadState(t, f)
+	if !slices.Equal(st.Attached, []string{"api-feature-login", "api-feature-login"}) {
+		t.Errorf("attached = %v", st.Attached)
+	}
+	if want := filepath.Join(filepath.Dir(repo), "api-feature-login"); len(st.Sessions) != 1 || st.Sessions[0].StartedIn != want {
+		t.Errorf("sessions = %+v, want one started in %s", st.Sessions, want)
+	}
+}
+
+func TestE2ENoteCommand(t *testing.T) {
+	bin, env, _ := e2eEnv(t, "alpha")
+	zp := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command(bin, args...)
+		cmd.Env = env
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("zp %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	zp("note", "alpha", "waiting", "on", "CI", "#prod")
+	if got := zp("note", "alpha"); got != "waiting on CI #prod" {
+		t.Errorf("zp note alpha = %q", got)
+	}
+	if got := zp("list", "--json"); !strings.Contains(got, `"note": "waiting on CI"`) || !strings.Contains(got, `"prod"`) {
+		t.Errorf("list --json lacks the note:\n%s", got)
+	}
+
+	zp("note", "alpha", "")
+	if got := zp("note", "alpha"); got != "" {
+		t.Errorf("cleared note = %q", got)
+	}
+	cmd := exec.Command(bin, "note", "missing", "x")
+	cmd.Env = env
+	if err := cmd.Run(); err == nil {
+		t.Error("noting a missing session should fail")
+	}
+}
+
+func TestE2ERenameAndKillCommandsKeepState(t *testing.T) {
+	bin, env, _ := e2eEnv(t, "alpha")
+	zp := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command(bin, args...)
+		cmd.Env = env
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("zp %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	// The note follows a rename.
+	zp("note", "alpha", "waiting on CI")
+	zp("rename", "alpha", "omega")
+	if got := zp("note", "omega"); got != "waiting on CI" {
+		t.Errorf("note after rename = %q", got)
+	}
+
+	// A new session reusing a killed one's name doesn't inherit its note.
+	zp("kill", "omega")
+	zp("attach", "omega")
+	if got := zp("note", "omega"); got != "" {
+		t.Errorf("note of a new session after kill = %q", got)
+	}
+}
+
+// stubRemote configures the hosts devbox and offline and puts a stand-in
+// ssh on PATH that answers `zp list --json` for devbox only. Every call is
+// logged to ssh.log next to bin. It returns env with the new PATH.
+func stubRemote(t *testing.T, bin string, env []string) []string {
+	t.Helper()
+	home := filepath.Dir(bin)
+	sshDir := filepath.Join(home, "bin")
+	script := `#!/bin/sh
+echo "$@" >> "$HOME/ssh.log"
+for a; do host=$prev; prev=$a; done
+[ "$host" = devbox ] || exit 255
+echo '{"sessions": [{"name": "api", "clients": 0, "started_in": "/srv/api", "active": false}]}'
+`
+	if err := os.MkdirAll(sshDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sshDir, "ssh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	configDir := filepath.Join(home, "config", "zpick")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := "[remote]\nhosts = [\"devbox\", \"offline\"]\n"
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return append(env, "PATH="+sshDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestE2EPickerRemoteHost(t *testing.T) {
+	bin, env, _ := e2eEnv(t, "alpha")
+	env = stubRemote(t, bin, env)
+
+	p := startPTY(t, bin, env)
+	p.expect("devbox")
+	p.expect("offline unreachable")
+	p.expect("skip")
+	p.send("2")
+
+	if got, want := p.wait(), "ssh -t devbox zp attach api"; got != want {
+		t.Errorf("eval'd command = %q, want %q", got, want)
+	}
+}
+
+func TestE2EPickerKillAllKeepsRemote(t *testing.T) {
+	bin, env, f := e2eEnv(t, "alpha", "beta")
+	env = append(stubRemote(t, bin, env), "ZPICK_NO_CONFIRM=1")
+
+	p := startPTY(t, bin, env)
+	p.expect("devbox")
+	p.expect("skip")
+	p.send("k")
+	p.expect("which session")
+	p.send("c")
+	p.expect("killed")
+	p.expect("skip")
+	p.send("\x1b")
+	p.wait()
+
+	if st := loadState(t, f); len(st.Sessions) != 0 {
+		t.Errorf("local sessions left: %+v", st.Sessions)
+	}
+	log, _ := os.ReadFile(filepath.Join(filepath.Dir(bin), "ssh.log"))
+	if !strings.Contains(string(log), "devbox") {
+		t.Fatal("the stand-in ssh was never called")
+	}
+	if strings.Contains(string(log), "zp kill") {
+		t.Errorf("kill all reached a remote host:\n%s", log)
+	}
+}
+
+func TestE2EPickerFilter(t *testing.T) {
+	bin, env, _ := e2eEnv(t, "alpha", "beta", "gamma")
+
+	p := startPTY(t, bin, env)
+	p.expect("skip")
+	p.send("/")
+	p.expect("cancel")
+	p.send("gm")
+	p.expect("gm")
+	p.send("\r")
+
+	if got, want := p.wait(), `ZPICK_SESSION='gamma' : fake attach 'gamma'`; got != want {
+		t.Errorf("eval'd command = %q, want %q", got, want)
+	}
+}
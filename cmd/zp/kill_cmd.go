@@ -1,9 +1,19 @@
 package main
 
+import (
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/state"
+)
+
 func runKill(name string) error {
 	b, err := loadBackend(true)
 	if err != nil {
 		return err
 	}
-	return b.Kill(name)
+	// Look up the owner first: once the session is gone it's the primary.
+	owner := backend.Owner(b, name)
+	if err := b.Kill(name); err != nil {
+		return err
+	}
+	return state.Forget(state.Key(owner.Name(), "", name))
 }
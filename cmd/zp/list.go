@@ -5,15 +5,39 @@ import (
 	"fmt"
 
 	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/state"
 )
 
 // ListResult is the JSON output format for `zpick list --json`.
 // Keeps zmosh_version for backwards compatibility, adds backend_version.
 type ListResult struct {
-	Sessions       []backend.Session `json:"sessions"`
-	Count          int               `json:"count"`
-	ZmoshVersion   string            `json:"zmosh_version,omitempty"`
-	BackendVersion string            `json:"backend_version,omitempty"`
+	Sessions       []ListSession `json:"sessions"`
+	Count          int           `json:"count"`
+	ZmoshVersion   string        `json:"zmosh_version,omitempty"`
+	BackendVersion string        `json:"backend_version,omitempty"`
+}
+
+// ListSession is a session in `zpick list --json`: the backend's fields
+// plus the note and tags zpick keeps for it.
+type ListSession struct {
+	backend.Session
+	Note string   `json:"note,omitempty"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+// withNotes pairs sessions with their notes.
+func withNotes(b backend.Backend, sessions []backend.Session) []ListSession {
+	notes := state.LoadNotes()
+	listed := make([]ListSession, len(sessions))
+	for i, s := range sessions {
+		owner := s.Backend
+		if owner == "" {
+			owner = b.Name()
+		}
+		n := notes[state.Key(owner, "", s.Name)]
+		listed[i] = ListSession{Session: s, Note: n.Text, Tags: n.Tags}
+	}
+	return listed
 }
 
 func runList() error {
@@ -31,7 +55,7 @@ func runList() error {
 			return err
 		}
 		result := ListResult{
-			Sessions: sessions,
+			Sessions: withNotes(b, sessions),
 			Count:    len(sessions),
 		}
 		if ver, err := b.Version(); err == nil {
@@ -59,12 +83,23 @@ func runList() error {
 		return nil
 	}
 
-	for _, s := range sessions {
+	for _, s := range withNotes(b, sessions) {
 		status := "."
 		if s.Active {
 			status = "*"
 		}
-		fmt.Printf("  %s%s  (%d clients)  %s\n", status, s.Name, s.Clients, s.StartedIn)
+		line := fmt.Sprintf("  %s%s", status, s.Name)
+		if s.Backend != "" {
+			line += fmt.Sprintf("  [%s]", s.Backend)
+		}
+		line += fmt.Sprintf("  (%d clients)  %s", s.Clients, s.StartedIn)
+		if s.Command != "" {
+			line += "  " + s.Command
+		}
+		if n := (state.Note{Text: s.Note, Tags: s.Tags}); !n.Empty() {
+			line += "  " + n.String()
+		}
+		fmt.Println(line)
 	}
 	return nil
 }
@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/nerveband/zpick/internal/backend"
 )
@@ -11,8 +12,8 @@ import (
 // These fields must remain present for backwards compatibility (additive only).
 func TestListJSONContract(t *testing.T) {
 	result := ListResult{
-		Sessions: []backend.Session{
-			{Name: "test", PID: 123, Clients: 1, StartedIn: "~/test", Active: true},
+		Sessions: []ListSession{
+			{Session: backend.Session{Name: "test", PID: 123, Clients: 1, StartedIn: "~/test", Active: true}, Note: "waiting on CI", Tags: []string{"prod"}},
 		},
 		Count:          1,
 		ZmoshVersion:   "0.4.2",
@@ -58,3 +59,34 @@ func TestListJSONContract(t *testing.T) {
 		}
 	}
 }
+
+// Metadata fields are additive: present when a backend reports them, omitted
+// otherwise so older consumers see the same shape as before.
+func TestListJSONMetadataFields(t *testing.T) {
+	result := ListResult{
+		Sessions: []ListSession{
+			{Session: backend.Session{Name: "rich", CreatedAt: time.Unix(1771652262, 0), LastActivity: time.Unix(1771655862, 0), Windows: 2, Command: "nvim"}, Note: "deploy", Tags: []string{"prod"}},
+			{Session: backend.Session{Name: "bare"}},
+		},
+		Count: 2,
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw struct {
+		Sessions []map[string]interface{} `json:"sessions"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"created_at", "last_activity", "windows", "command", "note", "tags"} {
+		if _, ok := raw.Sessions[0][field]; !ok {
+			t.Errorf("missing sessions[0].%q", field)
+		}
+		if _, ok := raw.Sessions[1][field]; ok {
+			t.Errorf("sessions[1].%q should be omitted when unset", field)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/nerveband/zpick/internal/update"
 
 	// Register all backends via init()
+	_ "github.com/nerveband/zpick/internal/backend/fake"
 	_ "github.com/nerveband/zpick/internal/backend/shpool"
 	_ "github.com/nerveband/zpick/internal/backend/tmux"
 	_ "github.com/nerveband/zpick/internal/backend/zellij"
@@ -44,7 +45,7 @@ func main() {
 		}
 	case "attach":
 		if len(os.Args) < 3 {
-			fmt.Fprintln(os.Stderr, "usage: zp attach <name> [--dir <path>]")
+			fmt.Fprintln(os.Stderr, "usage: zp attach <name> [--dir <path>] [--template <t>]")
 			os.Exit(1)
 		}
 		if err := runAttach(os.Args[2:]); err != nil {
@@ -60,6 +61,38 @@ func main() {
 			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
 			os.Exit(1)
 		}
+	case "rename":
+		if len(os.Args) < 4 {
+			fmt.Fprintln(os.Stderr, "usage: zp rename <old> <new>")
+			os.Exit(1)
+		}
+		if err := runRename(os.Args[2], os.Args[3]); err != nil {
+			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
+			os.Exit(1)
+		}
+	case "note":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(os.Stderr, "usage: zp note <name> [text]")
+			os.Exit(1)
+		}
+		if err := runNote(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
+			os.Exit(1)
+		}
+	case "worktree":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(os.Stderr, "usage: zp worktree <branch>")
+			os.Exit(1)
+		}
+		if err := runWorktree(os.Args[2]); err != nil {
+			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
+			os.Exit(1)
+		}
+	case "config":
+		if err := runConfig(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
+			os.Exit(1)
+		}
 	case "guard":
 		if err := runGuard(os.Args[2:]); err != nil {
 			fmt.Fprintf(os.Stderr, "zp: %v\n", err)
@@ -155,7 +188,7 @@ func shouldCheckUpdates(args []string) bool {
 	}
 	switch args[0] {
 	case "version", "upgrade", "post-upgrade-hook", "in-session", "should-autostart", "--help", "-h", "help", "guard", "autorun", "resume",
-		"install-guard", "remove-hook", "remove-guard":
+		"install-guard", "remove-hook", "remove-guard", "config", "note":
 		return false
 	}
 	for _, arg := range args[1:] {
@@ -175,6 +208,10 @@ Usage:
   zp check          Check dependencies (--json for machine-readable)
   zp attach <n>     Attach or create session
   zp kill <name>    Kill a session
+  zp rename <o> <n> Rename a session (tmux, zellij)
+  zp worktree <b>   Attach or create a session in branch b's git worktree
+  zp note <n> [t]   Show or set a session's note (#words are tags)
+  zp config         Show settings (get/set <key>, edit, path)
   zp guard          Explain session guard and show commands
   zp install-hook   Add shell hook to .zshrc/.bashrc/.config/fish
   zp install-guard  Add guard wrappers (installs hook if missing)
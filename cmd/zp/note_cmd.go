@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/state"
+)
+
+// runNote prints the note of session args[0], or replaces it with the rest
+// of args. Words starting with # are tags; an empty note removes it.
+func runNote(args []string) error {
+	b, err := loadBackend(true)
+	if err != nil {
+		return err
+	}
+	name := args[0]
+	if !sessionExists(b, name) {
+		return fmt.Errorf("no session %q", name)
+	}
+	key := state.Key(backend.Owner(b, name).Name(), "", name)
+	if len(args) == 1 {
+		if n := state.LoadNotes()[key]; !n.Empty() {
+			fmt.Println(n)
+		}
+		return nil
+	}
+	return state.SetNote(key, state.ParseNote(strings.Join(args[1:], " ")))
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// ptyProc is a zp process whose controlling terminal is a pseudo-terminal,
+// so the picker's /dev/tty UI can be driven from a test.
+type ptyProc struct {
+	t      *testing.T
+	cmd    *exec.Cmd
+	master *os.File
+	stdout bytes.Buffer
+
+	mu     sync.Mutex
+	screen bytes.Buffer
+}
+
+// startPTY runs bin with args and env on a new pty. Stdout is captured
+// separately: it's what the shell hook would eval.
+func startPTY(t *testing.T, bin string, env []string, args ...string) *ptyProc {
+	t.Helper()
+	master, slave, err := openPTY()
+	if err != nil {
+		t.Skipf("no pty available: %v", err)
+	}
+	defer slave.Close()
+
+	p := &ptyProc{t: t, master: master}
+	p.cmd = exec.Command(bin, args...)
+	p.cmd.Env = env
+	p.cmd.Stdin = slave
+	p.cmd.Stdout = &p.stdout
+	p.cmd.Stderr = slave
+	p.cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+	if err := p.cmd.Start(); err != nil {
+		master.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		master.Close()
+		if p.cmd.ProcessState == nil {
+			p.cmd.Process.Kill()
+			p.cmd.Wait()
+		}
+	})
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := master.Read(buf)
+			p.mu.Lock()
+			p.screen.Write(buf[:n])
+			p.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// expect waits until the terminal output contains s, then consumes the
+// output up to and including it so the next expect only sees newer output.
+func (p *ptyProc) expect(s string) {
+	p.t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		p.mu.Lock()
+		i := strings.Index(p.screen.String(), s)
+		if i >= 0 {
+			p.screen.Next(i + len(s))
+		}
+		p.mu.Unlock()
+		if i >= 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.t.Fatalf("timed out waiting for %q; screen:\n%s", s, p.screen.String())
+}
+
+// send types keys into the terminal.
+func (p *ptyProc) send(keys string) {
+	p.t.Helper()
+	if _, err := p.master.Write([]byte(keys)); err != nil {
+		p.t.Fatal(err)
+	}
+}
+
+// expectRaw waits until zp has put the terminal in raw mode, i.e. it's
+// waiting for a keypress. Keys sent before then can be eaten by whatever
+// read the terminal last.
+func (p *ptyProc) expectRaw() {
+	p.t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		var tio syscall.Termios
+		if err := ioctl(p.master.Fd(), syscall.TCGETS, uintptr(unsafe.Pointer(&tio))); err != nil {
+			p.t.Fatal(err)
+		}
+		if tio.Lflag&syscall.ICANON == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	p.t.Fatal("timed out waiting for the terminal to go raw")
+}
+
+// resize sets the terminal size, which sends zp a SIGWINCH.
+func (p *ptyProc) resize(rows, cols uint16) {
+	p.t.Helper()
+	ws := struct{ rows, cols, xpixel, ypixel uint16 }{rows, cols, 0, 0}
+	if err := ioctl(p.master.Fd(), syscall.TIOCSWINSZ, uintptr(unsafe.Pointer(&ws))); err != nil {
+		p.t.Fatal(err)
+	}
+}
+
+// wait waits for the process to exit and returns what it printed to stdout.
+func (p *ptyProc) wait() string {
+	p.t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- p.cmd.Wait() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			p.t.Fatalf("zp exited with %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		p.cmd.Process.Kill()
+		p.t.Fatal("timed out waiting for zp to exit")
+	}
+	return p.stdout.String()
+}
+
+// openPTY opens a Linux pseudo-terminal pair.
+func openPTY() (master, slave *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var unlock int32
+	if err := ioctl(master.Fd(), syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	var n uint32
+	if err := ioctl(master.Fd(), syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	slave, err = os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	return master, slave, nil
+}
+
+func ioctl(fd, req, arg uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, arg); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/state"
+)
+
+func runRename(oldName, newName string) error {
+	b, err := loadBackend(true)
+	if err != nil {
+		return err
+	}
+	owner := backend.Owner(b, oldName)
+	r, ok := backend.As[backend.Renamer](owner)
+	if !ok {
+		return fmt.Errorf("%s does not support renaming sessions", owner.Name())
+	}
+	if err := r.Rename(oldName, newName); err != nil {
+		return err
+	}
+	return state.Rename(state.Key(owner.Name(), "", oldName), state.Key(owner.Name(), "", newName))
+}
@@ -3,6 +3,9 @@ package main
 import (
 	"fmt"
 
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/project"
+	"github.com/nerveband/zpick/internal/remote"
 	"github.com/nerveband/zpick/internal/switcher"
 )
 
@@ -21,14 +24,28 @@ func runResume() error {
 		return nil
 	}
 
-	cmd := b.AttachCommand(target.Name, "")
+	tb := backend.Named(b, target.Backend)
+	cmd := tb.AttachCommand(target.Name, "")
 
 	switch target.Action {
 	case "attach", "new":
+		if target.Host != "" {
+			fmt.Print(remote.AttachCommand(target.Host, target.Name))
+			return nil
+		}
+		if target.Action == "new" && target.Template != "" {
+			_, tcmd, err := templateCommand(tb, target.Name, target.Dir, target.Template)
+			if err != nil {
+				return fmt.Errorf("template %s: %w", target.Template, err)
+			}
+			fmt.Printf("ZPICK_SESSION=%s sh -c %s", backend.ShellQuote(target.Name), backend.ShellQuote(tcmd))
+			return nil
+		}
+		env := project.ShellPrefix(target.Env, target.Command, target.Action == "new")
 		if target.Dir != "" {
-			fmt.Printf("cd %q && ZPICK_SESSION=%q %s", target.Dir, target.Name, cmd)
+			fmt.Printf("cd %s && %sZPICK_SESSION=%s %s", backend.ShellQuote(target.Dir), env, backend.ShellQuote(target.Name), cmd)
 		} else {
-			fmt.Printf("ZPICK_SESSION=%q %s", target.Name, cmd)
+			fmt.Printf("%sZPICK_SESSION=%s %s", env, backend.ShellQuote(target.Name), cmd)
 		}
 	default:
 		// Unknown action — silent, not an error.
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nerveband/zpick/internal/git"
+	"github.com/nerveband/zpick/internal/picker"
+)
+
+// runWorktree attaches to (or creates) the session for branch's worktree of
+// the current repository, adding the worktree first if there isn't one.
+func runWorktree(branch string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	root := git.Root(cwd)
+	if root == "" {
+		return fmt.Errorf("%s is not in a git repository", cwd)
+	}
+	trees, err := git.Worktrees(root)
+	if err != nil {
+		return err
+	}
+	if len(trees) == 0 {
+		return fmt.Errorf("no worktrees in %s", root)
+	}
+
+	main, dir := trees[0].Path, ""
+	for _, w := range trees {
+		if w.Branch == branch {
+			dir = w.Path
+			break
+		}
+	}
+	if dir == "" {
+		dir = git.WorktreeDir(main, branch)
+		if err := git.AddWorktree(root, dir, branch); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "zp: created worktree %s\n", dir)
+	}
+	b, err := loadBackend(true)
+	if err != nil {
+		return err
+	}
+	return attach(b, picker.WorktreeName(b, main, branch), dir, "")
+}
@@ -0,0 +1,40 @@
+// Package autorun encodes a command line into ZPICK_AUTORUN so the shell
+// hook can run it as soon as the new session's shell starts.
+package autorun
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// EnvVar carries the encoded argv into the new session.
+const EnvVar = "ZPICK_AUTORUN"
+
+// Encode returns the ZPICK_AUTORUN value for argv, or "" if argv is empty.
+func Encode(argv []string) string {
+	if len(argv) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(argv)
+	if err != nil {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// Decode decodes a ZPICK_AUTORUN value back into argv.
+func Decode(encoded string) ([]string, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	var argv []string
+	if err := json.Unmarshal(data, &argv); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("empty argv")
+	}
+	return argv, nil
+}
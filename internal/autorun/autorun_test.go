@@ -0,0 +1,64 @@
+package autorun
+
+import (
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		argv []string
+	}{
+		{"simple", []string{"claude"}},
+		{"with args", []string{"claude", "--model", "opus"}},
+		{"with spaces", []string{"my-tool", "arg with spaces"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := Encode(tt.argv)
+			if encoded == "" {
+				t.Fatal("Encode returned empty")
+			}
+
+			decoded, err := Decode(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(decoded) != len(tt.argv) {
+				t.Fatalf("expected %d args, got %d", len(tt.argv), len(decoded))
+			}
+			for i, arg := range tt.argv {
+				if decoded[i] != arg {
+					t.Errorf("arg[%d]: expected %q, got %q", i, arg, decoded[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeEmpty(t *testing.T) {
+	if Encode(nil) != "" {
+		t.Error("nil argv should return empty")
+	}
+	if Encode([]string{}) != "" {
+		t.Error("empty argv should return empty")
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	if _, err := Decode("not-base64!!!"); err == nil {
+		t.Error("invalid base64 should error")
+	}
+
+	// Valid base64 but not JSON
+	if _, err := Decode("aGVsbG8="); err == nil {
+		t.Error("non-JSON should error")
+	}
+
+	// Valid base64 JSON but empty array
+	if _, err := Decode("W10="); err == nil {
+		t.Error("empty array should error")
+	}
+}
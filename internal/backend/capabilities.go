@@ -0,0 +1,121 @@
+package backend
+
+import "strings"
+
+// Optional capabilities. Backends implement whichever of these they support;
+// callers discover them with a type assertion (or As/Supports) and hide the
+// feature when it is missing instead of failing.
+
+// Renamer is implemented by backends that can rename a session in place.
+type Renamer interface {
+	Rename(oldName, newName string) error
+}
+
+// DetachedCreator is implemented by backends that can create a session
+// without attaching to it.
+type DetachedCreator interface {
+	CreateDetached(name, dir string) error
+}
+
+// Previewer is implemented by backends that can dump the recent output of a
+// session without attaching to it.
+type Previewer interface {
+	// Preview returns up to the last n lines of the session's visible output.
+	Preview(name string, n int) (string, error)
+}
+
+// WindowLister is implemented by backends with windows (tmux) or tabs (zellij).
+type WindowLister interface {
+	Windows(name string) ([]Window, error)
+}
+
+// Templater is implemented by backends that can build a session from a
+// layout template.
+type Templater interface {
+	// TemplateCommand returns a shell command that creates session name in
+	// dir laid out as t (or attaches to it if it already exists).
+	TemplateCommand(name, dir string, t *Template) (string, error)
+}
+
+// Detacher is implemented by backends that can disconnect the clients of a
+// session from outside it, leaving the session running.
+type Detacher interface {
+	DetachClients(name string) error
+}
+
+// NameChecker is implemented by backends that know which characters a
+// session name can't contain.
+type NameChecker interface {
+	// UnsafeNameChars returns the characters a session name must not
+	// contain, because the backend rejects or rewrites them.
+	UnsafeNameChars() string
+}
+
+// UnsafeNameChars returns the characters b can't take in a session name.
+// Backends that don't say are taken to use the name as a file name (most
+// keep a socket per session), so '/'.
+func UnsafeNameChars(b Backend) string {
+	if c, ok := As[NameChecker](b); ok {
+		return c.UnsafeNameChars()
+	}
+	return "/"
+}
+
+// SafeName writes each character b can't take in a session name as '-'.
+func SafeName(b Backend, name string) string {
+	unsafe := UnsafeNameChars(b)
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(unsafe, r) {
+			return '-'
+		}
+		return r
+	}, name)
+}
+
+// Window describes a window or tab inside a session.
+type Window struct {
+	Index  int    `json:"index"`
+	Name   string `json:"name"`
+	Panes  int    `json:"panes,omitempty"`
+	Active bool   `json:"active"`
+}
+
+// As returns b as capability T. Aggregates are not unwrapped; call Resolve or
+// Owner first to get the concrete backend for a session.
+func As[T any](b Backend) (T, bool) {
+	c, ok := b.(T)
+	return c, ok
+}
+
+// Supports reports whether b, or for an aggregate any of its members,
+// implements capability T.
+func Supports[T any](b Backend) bool {
+	for _, m := range Members(b) {
+		if _, ok := m.(T); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Provider returns the backend behind b that implements capability T: b
+// itself, or the first member of an aggregate that does, so the primary
+// when it can. Use it for actions that create a session, where Supports
+// decided whether to offer them.
+func Provider[T any](b Backend) (Backend, bool) {
+	for _, m := range Members(b) {
+		if _, ok := m.(T); ok {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// TailLines returns the last n non-trailing-blank lines of s.
+func TailLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, " \t\r\n"), "\n")
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,68 @@
+package backend
+
+import "testing"
+
+// renamingStub adds the Renamer capability to stubBackend.
+type renamingStub struct{ stubBackend }
+
+func (r *renamingStub) Rename(oldName, newName string) error { return nil }
+
+func TestSupports(t *testing.T) {
+	plain := &stubBackend{name: "shpool"}
+	renaming := &renamingStub{stubBackend{name: "tmux"}}
+
+	if Supports[Renamer](plain) {
+		t.Error("plain backend should not support Renamer")
+	}
+	if !Supports[Renamer](renaming) {
+		t.Error("renaming backend should support Renamer")
+	}
+	if !Supports[Renamer](NewMulti([]Backend{plain, renaming})) {
+		t.Error("aggregate should support Renamer when any member does")
+	}
+	if Supports[Previewer](NewMulti([]Backend{plain, renaming})) {
+		t.Error("aggregate should not support Previewer when no member does")
+	}
+}
+
+func TestProvider(t *testing.T) {
+	plain := &stubBackend{name: "shpool"}
+	renaming := &renamingStub{stubBackend{name: "tmux"}}
+
+	if got, ok := Provider[Renamer](NewMulti([]Backend{plain, renaming})); !ok || got != renaming {
+		t.Errorf("Provider[Renamer] = %v, %v; want the tmux member, not the primary", got, ok)
+	}
+	if got, ok := Provider[Renamer](renaming); !ok || got != renaming {
+		t.Errorf("Provider[Renamer] of a plain backend = %v, %v", got, ok)
+	}
+	if _, ok := Provider[Renamer](plain); ok {
+		t.Error("Provider[Renamer] should fail when nothing implements it")
+	}
+}
+
+func TestAs(t *testing.T) {
+	if _, ok := As[Renamer](&renamingStub{}); !ok {
+		t.Error("As[Renamer] should succeed")
+	}
+	if _, ok := As[Renamer](NewMulti([]Backend{&renamingStub{}})); ok {
+		t.Error("As should not unwrap aggregates")
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{in: "a\nb\nc\n\n\n", n: 2, want: "b\nc"},
+		{in: "a\nb", n: 5, want: "a\nb"},
+		{in: "a\nb\nc", n: 0, want: "a\nb\nc"},
+		{in: "", n: 3, want: ""},
+	}
+	for _, tt := range tests {
+		if got := TailLines(tt.in, tt.n); got != tt.want {
+			t.Errorf("TailLines(%q, %d) = %q, want %q", tt.in, tt.n, got, tt.want)
+		}
+	}
+}
@@ -4,19 +4,32 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+
+	"github.com/nerveband/zpick/internal/config"
 )
 
-// validBackends is the list of recognized backend names.
+// BackendEnvVar overrides the configured backend for one invocation, e.g.
+// ZPICK_BACKEND=fake in end-to-end tests.
+const BackendEnvVar = "ZPICK_BACKEND"
+
+// validBackends is the list of built-in backend names.
 var validBackends = []string{"zmosh", "zmx", "tmux", "shpool", "zellij"}
 
+// reservedBackends are names no plugin may take: the aggregate view and the
+// fake backend used by tests.
+var reservedBackends = []string{AggregateName, "fake"}
+
+// knownBackends returns the built-in backends followed by any discovered
+// zp-backend-<name> plugins.
+func knownBackends() []string {
+	return append(append([]string{}, validBackends...), pluginNames()...)
+}
+
 // ConfigDir returns the zpick config directory, respecting XDG_CONFIG_HOME.
 func ConfigDir() string {
-	if d := os.Getenv("XDG_CONFIG_HOME"); d != "" {
-		return filepath.Join(d, "zpick")
-	}
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".config", "zpick")
+	return config.Dir()
 }
 
 // ReadBackendName returns the configured backend name, or empty if not configured.
@@ -25,71 +38,43 @@ func ReadBackendName() (string, error) {
 }
 
 // readBackendConfig reads the backend name from the config file.
-// Returns empty string if the file doesn't exist.
+// Returns empty string if none is configured.
 func readBackendConfig() (string, error) {
-	data, err := os.ReadFile(filepath.Join(ConfigDir(), "backend"))
-	if os.IsNotExist(err) {
-		return "", nil
-	}
+	c, err := config.Load()
 	if err != nil {
 		return "", err
 	}
-	return strings.TrimSpace(string(data)), nil
+	return c.Backend.Name, nil
 }
 
 // SetBackend writes the backend name to the config file.
 func SetBackend(name string) error {
 	if !isValidBackend(name) {
-		return fmt.Errorf("unknown backend %q (valid: %s)", name, strings.Join(validBackends, ", "))
-	}
-	dir := ConfigDir()
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
+		return fmt.Errorf("unknown backend %q (valid: %s, %s)", name, strings.Join(knownBackends(), ", "), AggregateName)
 	}
-	return os.WriteFile(filepath.Join(dir, "backend"), []byte(name+"\n"), 0644)
+	return config.Update(func(c *config.Config) error {
+		c.Backend.Name = name
+		return nil
+	})
 }
 
 // SetUDP writes the zmosh UDP configuration.
 func SetUDP(enabled bool, host string) error {
-	dir := ConfigDir()
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-	var b strings.Builder
-	if enabled {
-		b.WriteString("enabled=true\n")
-	} else {
-		b.WriteString("enabled=false\n")
-	}
-	if host != "" {
-		fmt.Fprintf(&b, "host=%s\n", host)
-	}
-	return os.WriteFile(filepath.Join(dir, "udp.conf"), []byte(b.String()), 0644)
+	return config.Update(func(c *config.Config) error {
+		c.UDP = config.UDPConfig{Enabled: enabled, Host: host}
+		return nil
+	})
 }
 
 // ReadUDP reads the zmosh UDP configuration.
 // Defaults: enabled=true, host="" (empty).
 func ReadUDP() (enabled bool, host string) {
-	data, err := os.ReadFile(filepath.Join(ConfigDir(), "udp.conf"))
-	if err != nil {
-		return true, "" // default: enabled, no host
-	}
-	enabled = true // default
-	for _, line := range strings.Split(string(data), "\n") {
-		line = strings.TrimSpace(line)
-		if k, v, ok := strings.Cut(line, "="); ok {
-			switch k {
-			case "enabled":
-				enabled = v == "true"
-			case "host":
-				host = v
-			}
-		}
-	}
-	return
+	c, _ := config.Load()
+	return c.UDP.Enabled, c.UDP.Host
 }
 
-// Detect returns the names of all available backends.
+// Detect returns the names of all available backends, including plugins
+// that report themselves available.
 func Detect() []string {
 	var found []string
 	for _, name := range validBackends {
@@ -97,6 +82,12 @@ func Detect() []string {
 			found = append(found, name)
 		}
 	}
+	plugins := DiscoverPlugins()
+	for _, name := range pluginNames() {
+		if ok, _ := NewPlugin(name, plugins[name]).Available(); ok {
+			found = append(found, name)
+		}
+	}
 	if found == nil {
 		found = []string{} // never return nil
 	}
@@ -107,12 +98,23 @@ func Detect() []string {
 // When interactive=true and no config exists with multiple backends available,
 // it prompts the user on /dev/tty.
 // When interactive=false, it auto-detects a single backend or returns an error.
+// $ZPICK_BACKEND, when set, takes precedence over the config file.
 func Load(interactive bool) (Backend, error) {
+	if name := os.Getenv(BackendEnvVar); name != "" {
+		if name == AggregateName {
+			return loadMulti()
+		}
+		return newBackend(name)
+	}
+
 	name, err := readBackendConfig()
 	if err != nil {
 		return nil, fmt.Errorf("reading backend config: %w", err)
 	}
 
+	if name == AggregateName {
+		return loadMulti()
+	}
 	if name != "" {
 		return newBackend(name)
 	}
@@ -147,13 +149,16 @@ func Register(name string, factory func() Backend) {
 	registry[name] = factory
 }
 
-// newBackend creates a Backend by name from the registry.
+// newBackend creates a Backend by name from the registry, falling back to a
+// zp-backend-<name> plugin.
 func newBackend(name string) (Backend, error) {
-	factory, ok := registry[name]
-	if !ok {
-		return nil, fmt.Errorf("unknown backend %q (not registered)", name)
+	if factory, ok := registry[name]; ok {
+		return factory(), nil
+	}
+	if path, ok := DiscoverPlugins()[name]; ok {
+		return NewPlugin(name, path), nil
 	}
-	return factory(), nil
+	return nil, fmt.Errorf("unknown backend %q (not registered)", name)
 }
 
 // promptBackend prompts the user to select a backend on /dev/tty.
@@ -195,6 +200,20 @@ func promptBackend(available []string) (Backend, error) {
 }
 
 func isValidBackend(name string) bool {
+	if name == AggregateName || isBuiltinBackend(name) {
+		return true
+	}
+	_, ok := DiscoverPlugins()[name]
+	return ok
+}
+
+// isReservedBackend reports whether a plugin named name would shadow a
+// built-in backend or a reserved name.
+func isReservedBackend(name string) bool {
+	return isBuiltinBackend(name) || slices.Contains(reservedBackends, name)
+}
+
+func isBuiltinBackend(name string) bool {
 	for _, v := range validBackends {
 		if v == name {
 			return true
@@ -236,12 +255,8 @@ func DetectShell() string {
 // ReadKeyMode returns the configured key mode ("numbers" or "letters").
 // Defaults to "numbers" if not configured.
 func ReadKeyMode() string {
-	data, err := os.ReadFile(filepath.Join(ConfigDir(), "keys"))
-	if err != nil {
-		return "numbers"
-	}
-	mode := strings.TrimSpace(string(data))
-	if mode == "letters" {
+	c, _ := config.Load()
+	if c.Keys.Mode == "letters" {
 		return "letters"
 	}
 	return "numbers"
@@ -252,9 +267,8 @@ func SetKeyMode(mode string) error {
 	if mode != "numbers" && mode != "letters" {
 		return fmt.Errorf("invalid key mode %q (valid: numbers, letters)", mode)
 	}
-	dir := ConfigDir()
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-	return os.WriteFile(filepath.Join(dir, "keys"), []byte(mode+"\n"), 0644)
+	return config.Update(func(c *config.Config) error {
+		c.Keys.Mode = mode
+		return nil
+	})
 }
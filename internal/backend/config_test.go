@@ -3,6 +3,7 @@ package backend
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -144,9 +145,9 @@ func TestSetAndReadKeyMode(t *testing.T) {
 	if mode != "letters" {
 		t.Errorf("expected 'letters', got %q", mode)
 	}
-	data, _ := os.ReadFile(filepath.Join(tmp, "zpick", "keys"))
-	if got := string(data); got != "letters\n" {
-		t.Errorf("file contents: %q", got)
+	data, _ := os.ReadFile(filepath.Join(tmp, "zpick", "config.toml"))
+	if !strings.Contains(string(data), "[keys]\nmode = \"letters\"\n") {
+		t.Errorf("file contents: %q", data)
 	}
 }
 
@@ -0,0 +1,179 @@
+// Package fake is a backend for end-to-end tests. It never runs a session
+// manager: sessions live in a JSON state file shared by every zp invocation
+// in the test, and attaches are recorded instead of exec'd. Select it with
+// ZPICK_BACKEND=fake.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+const (
+	// StateEnvVar overrides the state file location.
+	StateEnvVar = "ZPICK_FAKE_STATE"
+	// SessionEnvVar marks a shell as "inside" the named fake session.
+	SessionEnvVar = "ZPICK_FAKE_SESSION"
+)
+
+func init() {
+	backend.Register("fake", func() backend.Backend { return New() })
+}
+
+// State is the contents of the state file.
+type State struct {
+	Sessions []backend.Session `json:"sessions"`
+	Attached []string          `json:"attached,omitempty"` // every Attach, in order
+	Killed   []string          `json:"killed,omitempty"`   // every Kill, in order
+	Detached []string          `json:"detached,omitempty"` // every DetachClients, in order
+}
+
+// Fake implements the Backend interface on top of a state file.
+type Fake struct {
+	path string
+}
+
+// New returns a Fake using $ZPICK_FAKE_STATE, or a file in the temp dir.
+func New() *Fake {
+	path := os.Getenv(StateEnvVar)
+	if path == "" {
+		path = filepath.Join(os.TempDir(), "zpick-fake-state.json")
+	}
+	return NewAt(path)
+}
+
+// NewAt returns a Fake using the state file at path.
+func NewAt(path string) *Fake { return &Fake{path: path} }
+
+func (f *Fake) Name() string          { return "fake" }
+func (f *Fake) BinaryName() string    { return "fake" }
+func (f *Fake) SessionEnvVar() string { return SessionEnvVar }
+
+func (f *Fake) InSession() bool {
+	return os.Getenv(SessionEnvVar) != ""
+}
+
+func (f *Fake) CurrentSessionName() string {
+	return os.Getenv(SessionEnvVar)
+}
+
+func (f *Fake) Available() (bool, error)             { return true, nil }
+func (f *Fake) Version() (string, error)             { return "fake", nil }
+func (f *Fake) FastList() ([]backend.Session, error) { return f.List() }
+
+func (f *Fake) List() ([]backend.Session, error) {
+	st, err := f.Load()
+	if err != nil {
+		return nil, err
+	}
+	return st.Sessions, nil
+}
+
+// Attach records the attach and creates the session if it doesn't exist.
+func (f *Fake) Attach(name string) error {
+	return f.update(func(st *State) error {
+		st.Attached = append(st.Attached, name)
+		if !slices.ContainsFunc(st.Sessions, func(s backend.Session) bool { return s.Name == name }) {
+			cwd, _ := os.Getwd()
+			st.Sessions = append(st.Sessions, backend.Session{Name: name, StartedIn: cwd, CreatedAt: time.Now()})
+		}
+		return nil
+	})
+}
+
+// AttachCommand returns a no-op shell command that names the session, so
+// tests can assert on what the shell hook would eval.
+func (f *Fake) AttachCommand(name, dir string) string {
+	cmd := fmt.Sprintf(": fake attach %s", backend.ShellQuote(name))
+	if dir != "" {
+		return fmt.Sprintf("cd %s && %s", backend.ShellQuote(dir), cmd)
+	}
+	return cmd
+}
+
+func (f *Fake) DetachCommand() string {
+	return ": fake detach"
+}
+
+func (f *Fake) Kill(name string) error {
+	return f.update(func(st *State) error {
+		i := slices.IndexFunc(st.Sessions, func(s backend.Session) bool { return s.Name == name })
+		if i < 0 {
+			return fmt.Errorf("session %q not found", name)
+		}
+		st.Sessions = slices.Delete(st.Sessions, i, i+1)
+		st.Killed = append(st.Killed, name)
+		return nil
+	})
+}
+
+// Rename renames a session in place.
+func (f *Fake) Rename(oldName, newName string) error {
+	return f.update(func(st *State) error {
+		i := slices.IndexFunc(st.Sessions, func(s backend.Session) bool { return s.Name == oldName })
+		if i < 0 {
+			return fmt.Errorf("session %q not found", oldName)
+		}
+		st.Sessions[i].Name = newName
+		return nil
+	})
+}
+
+// DetachClients records the detach and marks the session idle.
+func (f *Fake) DetachClients(name string) error {
+	return f.update(func(st *State) error {
+		i := slices.IndexFunc(st.Sessions, func(s backend.Session) bool { return s.Name == name })
+		if i < 0 {
+			return fmt.Errorf("session %q not found", name)
+		}
+		st.Sessions[i].Active = false
+		st.Sessions[i].Clients = 0
+		st.Detached = append(st.Detached, name)
+		return nil
+	})
+}
+
+// Load reads the state file. A missing file is an empty state.
+func (f *Fake) Load() (*State, error) {
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("fake state %s: %w", f.path, err)
+	}
+	return &st, nil
+}
+
+// Save writes the state file.
+func (f *Fake) Save(st *State) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(f.path, data, 0644)
+}
+
+func (f *Fake) update(fn func(*State) error) error {
+	st, err := f.Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(st); err != nil {
+		return err
+	}
+	return f.Save(st)
+}
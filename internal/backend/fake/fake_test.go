@@ -0,0 +1,106 @@
+package fake
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+var (
+	_ backend.Backend  = (*Fake)(nil)
+	_ backend.Detacher = (*Fake)(nil)
+)
+
+func TestFakeAttachCreatesAndRecords(t *testing.T) {
+	f := NewAt(filepath.Join(t.TempDir(), "state.json"))
+
+	if err := f.Attach("api"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Attach("api"); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := f.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Name != "api" {
+		t.Errorf("List() = %+v, want one api session", sessions)
+	}
+	st, err := f.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.Attached) != 2 {
+		t.Errorf("Attached = %v, want two attaches", st.Attached)
+	}
+}
+
+func TestFakeKill(t *testing.T) {
+	f := NewAt(filepath.Join(t.TempDir(), "state.json"))
+	if err := f.Save(&State{Sessions: []backend.Session{{Name: "a"}, {Name: "b"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Kill("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Kill("missing"); err == nil {
+		t.Error("Kill(missing) should fail")
+	}
+
+	st, err := f.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.Sessions) != 1 || st.Sessions[0].Name != "b" {
+		t.Errorf("Sessions = %+v", st.Sessions)
+	}
+	if len(st.Killed) != 1 || st.Killed[0] != "a" {
+		t.Errorf("Killed = %v", st.Killed)
+	}
+}
+
+func TestFakeDetachClients(t *testing.T) {
+	f := NewAt(filepath.Join(t.TempDir(), "state.json"))
+	if err := f.Save(&State{Sessions: []backend.Session{{Name: "a", Active: true, Clients: 2}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.DetachClients("a"); err != nil {
+		t.Fatal(err)
+	}
+	st, err := f.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := st.Sessions[0]; s.Active || s.Clients != 0 {
+		t.Errorf("session still attached: %+v", s)
+	}
+	if len(st.Detached) != 1 || st.Detached[0] != "a" {
+		t.Errorf("Detached = %v", st.Detached)
+	}
+}
+
+func TestFakeInSession(t *testing.T) {
+	t.Setenv(SessionEnvVar, "api")
+	f := New()
+	if !f.InSession() || f.CurrentSessionName() != "api" {
+		t.Error("fake should report the session from " + SessionEnvVar)
+	}
+}
+
+func TestFakeSelectedByEnv(t *testing.T) {
+	t.Setenv(backend.BackendEnvVar, "fake")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	b, err := backend.Load(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Name() != "fake" {
+		t.Errorf("Load() = %s, want fake", b.Name())
+	}
+}
@@ -46,39 +46,41 @@ func ShellCommand(name string) string {
 		return name
 	}
 	if path, err := LookPath(name); err == nil {
-		return shellQuote(path)
+		return ShellQuote(path)
 	}
 	return name
 }
 
 func extraLookupCandidates(name string) []string {
-	seen := map[string]struct{}{}
 	var candidates []string
+	for _, dir := range extraLookupDirs() {
+		candidates = append(candidates, filepath.Join(dir, name))
+	}
+	return candidates
+}
 
-	if home, err := os.UserHomeDir(); err == nil && home != "" {
-		for _, dir := range []string{
-			filepath.Join(home, ".local", "bin"),
-			filepath.Join(home, ".cargo", "bin"),
-		} {
-			candidate := filepath.Join(dir, name)
-			if _, ok := seen[candidate]; ok {
-				continue
-			}
-			seen[candidate] = struct{}{}
-			candidates = append(candidates, candidate)
+// extraLookupDirs returns the fallback install directories searched after PATH.
+func extraLookupDirs() []string {
+	seen := map[string]struct{}{}
+	var dirs []string
+
+	add := func(dir string) {
+		if _, ok := seen[dir]; ok {
+			return
 		}
+		seen[dir] = struct{}{}
+		dirs = append(dirs, dir)
 	}
 
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		add(filepath.Join(home, ".local", "bin"))
+		add(filepath.Join(home, ".cargo", "bin"))
+	}
 	for _, dir := range extraSearchDirs {
-		candidate := filepath.Join(dir, name)
-		if _, ok := seen[candidate]; ok {
-			continue
-		}
-		seen[candidate] = struct{}{}
-		candidates = append(candidates, candidate)
+		add(dir)
 	}
 
-	return candidates
+	return dirs
 }
 
 func isExecutableFile(path string) bool {
@@ -89,6 +91,7 @@ func isExecutableFile(path string) bool {
 	return info.Mode()&0o111 != 0
 }
 
-func shellQuote(s string) string {
+// ShellQuote single-quotes s for use in a shell command.
+func ShellQuote(s string) string {
 	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
 }
@@ -42,7 +42,7 @@ func TestShellCommandUsesAbsolutePathWhenOnlyFallbackExists(t *testing.T) {
 	}
 
 	got := ShellCommand("zp-test")
-	want := shellQuote(cmdPath)
+	want := ShellQuote(cmdPath)
 	if got != want {
 		t.Fatalf("ShellCommand = %q, want %q", got, want)
 	}
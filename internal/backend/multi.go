@@ -0,0 +1,272 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AggregateName is the backend name that selects the aggregated view across
+// every detected backend.
+const AggregateName = "all"
+
+// Multi merges several backends into a single Backend. Sessions returned by
+// List and FastList are tagged with the backend they came from, and per-session
+// operations are routed to the owning backend. New sessions go to the primary
+// (first) backend.
+type Multi struct {
+	backends []Backend
+	failed   []string // members whose last listing failed
+}
+
+// NewMulti creates an aggregate over the given backends. The first backend is
+// the primary one used for new sessions.
+func NewMulti(backends []Backend) *Multi {
+	return &Multi{backends: backends}
+}
+
+// Backends returns the member backends in priority order.
+func (m *Multi) Backends() []Backend { return m.backends }
+
+// Failed returns the names of the members the last List or FastList
+// skipped because they failed, so their sessions are missing from it.
+func (m *Multi) Failed() []string { return m.failed }
+
+// Member returns the member backend with the given name, or nil.
+func (m *Multi) Member(name string) Backend {
+	for _, b := range m.backends {
+		if b.Name() == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// Owner returns the member backend that currently has a session with the given
+// name, falling back to the primary backend.
+func (m *Multi) Owner(name string) Backend {
+	for _, b := range m.backends {
+		sessions, err := b.FastList()
+		if err != nil {
+			continue
+		}
+		for _, s := range sessions {
+			if s.Name == name {
+				return b
+			}
+		}
+	}
+	return m.primary()
+}
+
+func (m *Multi) primary() Backend {
+	return m.backends[0]
+}
+
+// current returns the member we are running inside of, or the primary.
+func (m *Multi) current() Backend {
+	for _, b := range m.backends {
+		if b.InSession() {
+			return b
+		}
+	}
+	return m.primary()
+}
+
+func (m *Multi) Name() string          { return AggregateName }
+func (m *Multi) BinaryName() string    { return m.primary().BinaryName() }
+func (m *Multi) SessionEnvVar() string { return m.current().SessionEnvVar() }
+
+func (m *Multi) InSession() bool {
+	for _, b := range m.backends {
+		if b.InSession() {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Multi) CurrentSessionName() string {
+	for _, b := range m.backends {
+		if b.InSession() {
+			return b.CurrentSessionName()
+		}
+	}
+	return ""
+}
+
+func (m *Multi) Available() (bool, error) {
+	var errs []error
+	for _, b := range m.backends {
+		ok, err := b.Available()
+		if ok {
+			return true, nil
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return false, errors.Join(errs...)
+}
+
+func (m *Multi) Version() (string, error) {
+	var parts []string
+	for _, b := range m.backends {
+		if ver, err := b.Version(); err == nil {
+			parts = append(parts, b.Name()+" "+ver)
+		}
+	}
+	if len(parts) == 0 {
+		return "", fmt.Errorf("no backend reported a version")
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+func (m *Multi) List() ([]Session, error) {
+	return m.merge(Backend.List)
+}
+
+func (m *Multi) FastList() ([]Session, error) {
+	return m.merge(Backend.FastList)
+}
+
+// merge calls list on every member and tags each session with its backend.
+// A failing member is skipped, and recorded for Failed, unless every member
+// fails.
+func (m *Multi) merge(list func(Backend) ([]Session, error)) ([]Session, error) {
+	var all []Session
+	var errs []error
+	m.failed = nil
+	for _, b := range m.backends {
+		sessions, err := list(b)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", b.Name(), err))
+			m.failed = append(m.failed, b.Name())
+			continue
+		}
+		for _, s := range sessions {
+			s.Backend = b.Name()
+			all = append(all, s)
+		}
+	}
+	if len(errs) == len(m.backends) {
+		return nil, errors.Join(errs...)
+	}
+	return all, nil
+}
+
+func (m *Multi) Attach(name string) error {
+	return m.Owner(name).Attach(name)
+}
+
+func (m *Multi) AttachCommand(name, dir string) string {
+	return m.Owner(name).AttachCommand(name, dir)
+}
+
+func (m *Multi) DetachCommand() string { return m.current().DetachCommand() }
+
+// UnsafeNameChars is the primary's, since new sessions are created there.
+func (m *Multi) UnsafeNameChars() string { return UnsafeNameChars(m.primary()) }
+
+func (m *Multi) Kill(name string) error {
+	return m.Owner(name).Kill(name)
+}
+
+// Resolve returns the backend that owns a session tagged with backendName.
+// For non-aggregate backends it returns b unchanged; for an aggregate it
+// returns the named member, or the primary member when backendName is empty
+// or unknown.
+func Resolve(b Backend, backendName string) Backend {
+	m, ok := b.(*Multi)
+	if !ok {
+		return b
+	}
+	if member := m.Member(backendName); member != nil {
+		return member
+	}
+	return m.primary()
+}
+
+// Named returns the backend called name for callers that pick a backend
+// explicitly (e.g. a project file): the matching member of an aggregate, b
+// itself, or a freshly loaded backend. It falls back to Resolve(b, "") when
+// name is empty or can't be loaded.
+func Named(b Backend, name string) Backend {
+	if name == "" || name == b.Name() {
+		return Resolve(b, "")
+	}
+	if m, ok := b.(*Multi); ok {
+		if member := m.Member(name); member != nil {
+			return member
+		}
+	}
+	if other, err := newBackend(name); err == nil {
+		return other
+	}
+	return Resolve(b, "")
+}
+
+// Owner returns the backend that owns the named session. For non-aggregate
+// backends it returns b unchanged.
+func Owner(b Backend, name string) Backend {
+	if m, ok := b.(*Multi); ok {
+		return m.Owner(name)
+	}
+	return b
+}
+
+// Members returns the concrete backends behind b: the members of an aggregate,
+// or b itself.
+func Members(b Backend) []Backend {
+	if m, ok := b.(*Multi); ok {
+		return m.Backends()
+	}
+	return []Backend{b}
+}
+
+// Failed returns the members of an aggregate whose last listing failed (see
+// Multi.Failed). A single backend's failure is the listing's error, so for
+// non-aggregate backends it returns nil.
+func Failed(b Backend) []string {
+	if m, ok := b.(*Multi); ok {
+		return m.Failed()
+	}
+	return nil
+}
+
+// loadMulti builds an aggregate over every detected backend.
+func loadMulti() (Backend, error) {
+	names := aggregateNames(Detect())
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no supported session manager found (install zmosh, zmx, tmux, shpool, or zellij)")
+	}
+	var backends []Backend
+	for _, name := range names {
+		b, err := newBackend(name)
+		if err != nil {
+			return nil, err
+		}
+		backends = append(backends, b)
+	}
+	return NewMulti(backends), nil
+}
+
+// aggregateNames filters detected backend names for the aggregate view.
+// zmosh and zmx share the same socket directory, so listing both would show
+// every session twice; zmosh wins when both are installed.
+func aggregateNames(detected []string) []string {
+	hasZmosh := false
+	for _, name := range detected {
+		if name == "zmosh" {
+			hasZmosh = true
+		}
+	}
+	var names []string
+	for _, name := range detected {
+		if name == "zmx" && hasZmosh {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
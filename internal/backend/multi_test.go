@@ -0,0 +1,190 @@
+package backend
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// stubBackend is a minimal Backend for exercising the aggregate.
+type stubBackend struct {
+	name      string
+	inSession bool
+	current   string
+	sessions  []Session
+	listErr   error
+	killed    []string
+}
+
+func (s *stubBackend) Name() string                          { return s.name }
+func (s *stubBackend) BinaryName() string                    { return s.name }
+func (s *stubBackend) SessionEnvVar() string                 { return s.name + "_SESSION" }
+func (s *stubBackend) InSession() bool                       { return s.inSession }
+func (s *stubBackend) CurrentSessionName() string            { return s.current }
+func (s *stubBackend) Available() (bool, error)              { return true, nil }
+func (s *stubBackend) Version() (string, error)              { return "1.0", nil }
+func (s *stubBackend) List() ([]Session, error)              { return s.sessions, s.listErr }
+func (s *stubBackend) FastList() ([]Session, error)          { return s.sessions, s.listErr }
+func (s *stubBackend) Attach(name string) error              { return nil }
+func (s *stubBackend) DetachCommand() string                 { return s.name + " detach" }
+func (s *stubBackend) AttachCommand(name, dir string) string { return s.name + " attach " + name }
+func (s *stubBackend) Kill(name string) error {
+	s.killed = append(s.killed, name)
+	return nil
+}
+
+var _ Backend = (*Multi)(nil)
+
+func TestMultiFastListTagsSessions(t *testing.T) {
+	tmux := &stubBackend{name: "tmux", sessions: []Session{{Name: "api"}}}
+	zmosh := &stubBackend{name: "zmosh", sessions: []Session{{Name: "web"}, {Name: "docs"}}}
+	m := NewMulti([]Backend{tmux, zmosh})
+
+	sessions, err := m.FastList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Session{
+		{Name: "api", Backend: "tmux"},
+		{Name: "web", Backend: "zmosh"},
+		{Name: "docs", Backend: "zmosh"},
+	}
+	if len(sessions) != len(want) {
+		t.Fatalf("expected %d sessions, got %d", len(want), len(sessions))
+	}
+	for i := range want {
+		if sessions[i] != want[i] {
+			t.Errorf("sessions[%d] = %+v, want %+v", i, sessions[i], want[i])
+		}
+	}
+}
+
+func TestMultiFastListSkipsFailingMember(t *testing.T) {
+	tmux := &stubBackend{name: "tmux", listErr: errors.New("no server")}
+	zmosh := &stubBackend{name: "zmosh", sessions: []Session{{Name: "web"}}}
+	m := NewMulti([]Backend{tmux, zmosh})
+
+	sessions, err := m.FastList()
+	if err != nil {
+		t.Fatalf("expected partial success, got %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Name != "web" {
+		t.Errorf("unexpected sessions %+v", sessions)
+	}
+	if got := Failed(m); !slices.Equal(got, []string{"tmux"}) {
+		t.Errorf("Failed() = %v, want [tmux]", got)
+	}
+
+	tmux.listErr = nil
+	if _, err := m.FastList(); err != nil || Failed(m) != nil {
+		t.Errorf("after a full listing: err %v, Failed() = %v", err, Failed(m))
+	}
+}
+
+func TestMultiFastListAllFail(t *testing.T) {
+	tmux := &stubBackend{name: "tmux", listErr: errors.New("boom")}
+	m := NewMulti([]Backend{tmux})
+
+	if _, err := m.FastList(); err == nil {
+		t.Error("expected error when every member fails")
+	}
+}
+
+func TestMultiRoutesToOwner(t *testing.T) {
+	tmux := &stubBackend{name: "tmux", sessions: []Session{{Name: "api"}}}
+	zmosh := &stubBackend{name: "zmosh", sessions: []Session{{Name: "web"}}}
+	m := NewMulti([]Backend{tmux, zmosh})
+
+	if got := m.AttachCommand("web", ""); got != "zmosh attach web" {
+		t.Errorf("AttachCommand(web) = %q", got)
+	}
+	if got := m.AttachCommand("new-one", ""); got != "tmux attach new-one" {
+		t.Errorf("AttachCommand(new-one) = %q, want primary backend", got)
+	}
+	if err := m.Kill("web"); err != nil {
+		t.Fatal(err)
+	}
+	if len(zmosh.killed) != 1 || len(tmux.killed) != 0 {
+		t.Errorf("kill routed wrong: tmux=%v zmosh=%v", tmux.killed, zmosh.killed)
+	}
+}
+
+func TestMultiInSessionUsesCurrentMember(t *testing.T) {
+	tmux := &stubBackend{name: "tmux"}
+	zmosh := &stubBackend{name: "zmosh", inSession: true, current: "web"}
+	m := NewMulti([]Backend{tmux, zmosh})
+
+	if !m.InSession() {
+		t.Error("expected InSession when a member is in session")
+	}
+	if got := m.CurrentSessionName(); got != "web" {
+		t.Errorf("CurrentSessionName() = %q, want web", got)
+	}
+	if got := m.DetachCommand(); got != "zmosh detach" {
+		t.Errorf("DetachCommand() = %q, want zmosh detach", got)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tmux := &stubBackend{name: "tmux"}
+	zmosh := &stubBackend{name: "zmosh"}
+	m := NewMulti([]Backend{tmux, zmosh})
+
+	if got := Resolve(m, "zmosh"); got != zmosh {
+		t.Errorf("Resolve(zmosh) = %v", got.Name())
+	}
+	if got := Resolve(m, ""); got != tmux {
+		t.Errorf("Resolve(\"\") = %v, want primary", got.Name())
+	}
+	if got := Resolve(tmux, "zmosh"); got != tmux {
+		t.Error("Resolve on a single backend should return it unchanged")
+	}
+}
+
+func TestNamed(t *testing.T) {
+	tmux := &stubBackend{name: "tmux"}
+	zmosh := &stubBackend{name: "zmosh"}
+	m := NewMulti([]Backend{tmux, zmosh})
+
+	if got := Named(m, "zmosh"); got != zmosh {
+		t.Errorf("Named(m, zmosh) = %v", got.Name())
+	}
+	if got := Named(tmux, ""); got != tmux {
+		t.Errorf("Named(tmux, \"\") = %v", got.Name())
+	}
+
+	registry["named-test"] = func() Backend { return &stubBackend{name: "named-test"} }
+	defer delete(registry, "named-test")
+	if got := Named(tmux, "named-test"); got.Name() != "named-test" {
+		t.Errorf("Named(tmux, named-test) = %v, want a loaded backend", got.Name())
+	}
+	if got := Named(tmux, "missing"); got != tmux {
+		t.Errorf("Named(tmux, missing) = %v, want fallback", got.Name())
+	}
+}
+
+func TestAggregateNamesDropsZmxWithZmosh(t *testing.T) {
+	got := aggregateNames([]string{"zmosh", "zmx", "tmux"})
+	if len(got) != 2 || got[0] != "zmosh" || got[1] != "tmux" {
+		t.Errorf("aggregateNames() = %v", got)
+	}
+	got = aggregateNames([]string{"zmx", "tmux"})
+	if len(got) != 2 || got[0] != "zmx" {
+		t.Errorf("aggregateNames() = %v", got)
+	}
+}
+
+func TestSetBackendAggregate(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if err := SetBackend(AggregateName); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readBackendConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != AggregateName {
+		t.Errorf("readBackendConfig() = %q, want %q", got, AggregateName)
+	}
+}
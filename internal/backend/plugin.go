@@ -0,0 +1,249 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PluginPrefix is the executable name prefix for external backends.
+// An executable "zp-backend-foo" on PATH provides the backend "foo".
+const PluginPrefix = "zp-backend-"
+
+// PluginProtocol is the version of the JSON protocol spoken with plugins.
+const PluginProtocol = 1
+
+// pluginTimeout bounds every plugin call so a hung plugin can't wedge the picker.
+var pluginTimeout = 5 * time.Second
+
+// pluginRequest is written to the plugin's stdin. The op is also passed as
+// the first argument so simple plugins can dispatch on "$1".
+type pluginRequest struct {
+	Protocol int    `json:"protocol"`
+	Op       string `json:"op"`
+	Name     string `json:"name,omitempty"` // session name for attach-command, kill
+	Dir      string `json:"dir,omitempty"`  // working dir for attach-command
+}
+
+// pluginResponse is read from the plugin's stdout. Only the fields relevant
+// to the op need to be set; a non-empty Error fails the call.
+type pluginResponse struct {
+	Protocol   int       `json:"protocol"`
+	Error      string    `json:"error,omitempty"`
+	Name       string    `json:"name,omitempty"`        // op "name"
+	SessionEnv string    `json:"session_env,omitempty"` // op "name"
+	Available  bool      `json:"available,omitempty"`   // op "available"
+	Version    string    `json:"version,omitempty"`     // op "version"
+	Sessions   []Session `json:"sessions,omitempty"`    // op "list"
+	Command    string    `json:"command,omitempty"`     // ops "attach-command", "detach-command"
+}
+
+// Plugin is a Backend implemented by an external zp-backend-<name> executable.
+type Plugin struct {
+	name string
+	path string
+	info *pluginResponse // cached "name" response
+}
+
+// NewPlugin creates a backend for the plugin executable at path.
+func NewPlugin(name, path string) *Plugin {
+	return &Plugin{name: name, path: path}
+}
+
+// DiscoverPlugins returns plugin backend names mapped to executable paths,
+// searching PATH first and then the fallback install directories. Plugins that
+// shadow a built-in or reserved backend name are ignored. The scan is done
+// once per process for a given search path.
+func DiscoverPlugins() map[string]string {
+	dirs := append(filepath.SplitList(os.Getenv("PATH")), extraLookupDirs()...)
+	key := strings.Join(dirs, string(os.PathListSeparator))
+
+	pluginCache.Lock()
+	defer pluginCache.Unlock()
+	if pluginCache.found == nil || pluginCache.key != key {
+		pluginCache.key, pluginCache.found = key, scanPlugins(dirs)
+	}
+	return maps.Clone(pluginCache.found)
+}
+
+// pluginCache holds the last DiscoverPlugins scan and the search path it
+// covered.
+var pluginCache struct {
+	sync.Mutex
+	key   string
+	found map[string]string
+}
+
+func scanPlugins(dirs []string) map[string]string {
+	found := map[string]string{}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := strings.CutPrefix(e.Name(), PluginPrefix)
+			if !ok || name == "" || isReservedBackend(name) {
+				continue
+			}
+			if _, seen := found[name]; seen {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if isExecutableFile(path) {
+				found[name] = path
+			}
+		}
+	}
+	return found
+}
+
+// pluginNames returns the discovered plugin names in sorted order.
+func pluginNames() []string {
+	var names []string
+	for name := range DiscoverPlugins() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p *Plugin) call(req pluginRequest) (pluginResponse, error) {
+	req.Protocol = PluginProtocol
+	data, err := json.Marshal(req)
+	if err != nil {
+		return pluginResponse{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, p.path, req.Op)
+	cmd.Stdin = bytes.NewReader(data)
+	out, err := cmd.Output()
+	if err != nil {
+		return pluginResponse{}, fmt.Errorf("plugin %s %s: %w", p.name, req.Op, err)
+	}
+
+	var resp pluginResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return pluginResponse{}, fmt.Errorf("plugin %s %s: invalid response: %w", p.name, req.Op, err)
+	}
+	if resp.Protocol != PluginProtocol {
+		return pluginResponse{}, fmt.Errorf("plugin %s speaks protocol %d, zp expects %d", p.name, resp.Protocol, PluginProtocol)
+	}
+	if resp.Error != "" {
+		return pluginResponse{}, fmt.Errorf("plugin %s %s: %s", p.name, req.Op, resp.Error)
+	}
+	return resp, nil
+}
+
+// describe returns the cached "name" response, querying the plugin once.
+func (p *Plugin) describe() pluginResponse {
+	if p.info == nil {
+		resp, err := p.call(pluginRequest{Op: "name"})
+		if err != nil {
+			resp = pluginResponse{}
+		}
+		p.info = &resp
+	}
+	return *p.info
+}
+
+func (p *Plugin) Name() string          { return p.name }
+func (p *Plugin) BinaryName() string    { return filepath.Base(p.path) }
+func (p *Plugin) SessionEnvVar() string { return p.describe().SessionEnv }
+
+func (p *Plugin) InSession() bool {
+	env := p.SessionEnvVar()
+	return env != "" && os.Getenv(env) != ""
+}
+
+func (p *Plugin) CurrentSessionName() string {
+	if env := p.SessionEnvVar(); env != "" {
+		return os.Getenv(env)
+	}
+	return ""
+}
+
+func (p *Plugin) Available() (bool, error) {
+	resp, err := p.call(pluginRequest{Op: "available"})
+	if err != nil {
+		return false, err
+	}
+	if !resp.Available {
+		return false, fmt.Errorf("%s reports it is not available", p.name)
+	}
+	return true, nil
+}
+
+func (p *Plugin) Version() (string, error) {
+	resp, err := p.call(pluginRequest{Op: "version"})
+	if err != nil {
+		return "", err
+	}
+	return resp.Version, nil
+}
+
+func (p *Plugin) List() ([]Session, error) {
+	resp, err := p.call(pluginRequest{Op: "list"})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Sessions, nil
+}
+
+// FastList is the same as List for plugins.
+func (p *Plugin) FastList() ([]Session, error) {
+	return p.List()
+}
+
+func (p *Plugin) Attach(name string) error {
+	resp, err := p.call(pluginRequest{Op: "attach-command", Name: name})
+	if err != nil {
+		return err
+	}
+	if resp.Command == "" {
+		return errors.New("plugin " + p.name + " returned an empty attach command")
+	}
+	return ExecCommand("/bin/sh", []string{"sh", "-c", resp.Command})
+}
+
+func (p *Plugin) AttachCommand(name, dir string) string {
+	resp, err := p.call(pluginRequest{Op: "attach-command", Name: name, Dir: dir})
+	if err != nil {
+		return errorCommand(err)
+	}
+	return resp.Command
+}
+
+func (p *Plugin) DetachCommand() string {
+	resp, err := p.call(pluginRequest{Op: "detach-command"})
+	if err != nil {
+		return errorCommand(err)
+	}
+	return resp.Command
+}
+
+func (p *Plugin) Kill(name string) error {
+	_, err := p.call(pluginRequest{Op: "kill", Name: name})
+	return err
+}
+
+// errorCommand returns a shell command that reports err when eval'd, for
+// methods that can only return a command string.
+func errorCommand(err error) string {
+	return "echo " + ShellQuote("zp: "+err.Error()) + " >&2"
+}
@@ -0,0 +1,149 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakePluginScript answers each protocol op with a canned response.
+const fakePluginScript = `#!/bin/sh
+cat >/dev/null
+case "$1" in
+  name) echo '{"protocol":1,"name":"wrap","session_env":"WRAP_SESSION"}' ;;
+  available) echo '{"protocol":1,"available":true}' ;;
+  version) echo '{"protocol":1,"version":"2.1"}' ;;
+  list) echo '{"protocol":1,"sessions":[{"name":"api","clients":1,"started_in":"~/api","active":true}]}' ;;
+  attach-command) echo '{"protocol":1,"command":"wrap attach api"}' ;;
+  detach-command) echo '{"protocol":1,"command":"wrap detach"}' ;;
+  kill) echo '{"protocol":1,"error":"session is locked"}' ;;
+  *) echo '{"protocol":1,"error":"unknown op"}' ;;
+esac
+`
+
+func installPlugin(t *testing.T, name, script string) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	path := filepath.Join(dir, PluginPrefix+name)
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDiscoverPlugins(t *testing.T) {
+	path := installPlugin(t, "wrap", fakePluginScript)
+	// Plugins shadowing a built-in or reserved name are ignored.
+	for _, name := range []string{"tmux", AggregateName, "fake"} {
+		if err := os.WriteFile(filepath.Join(filepath.Dir(path), PluginPrefix+name), []byte(fakePluginScript), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	plugins := DiscoverPlugins()
+	if plugins["wrap"] != path {
+		t.Errorf("DiscoverPlugins()[wrap] = %q, want %q", plugins["wrap"], path)
+	}
+	for _, name := range []string{"tmux", AggregateName, "fake"} {
+		if _, ok := plugins[name]; ok {
+			t.Errorf("plugin shadowing %q should be ignored", name)
+		}
+	}
+
+	// The scan is cached until the search path changes.
+	late := filepath.Join(filepath.Dir(path), PluginPrefix+"late")
+	if err := os.WriteFile(late, []byte(fakePluginScript), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := DiscoverPlugins()["late"]; ok {
+		t.Error("DiscoverPlugins() should reuse its scan for the same PATH")
+	}
+	t.Setenv("PATH", os.Getenv("PATH")+string(os.PathListSeparator))
+	if DiscoverPlugins()["late"] != late {
+		t.Error("DiscoverPlugins() should rescan when PATH changes")
+	}
+}
+
+func TestDetectSkipsUnavailablePlugin(t *testing.T) {
+	script := strings.Replace(fakePluginScript, `"available":true`, `"available":false`, 1)
+	installPlugin(t, "down", script)
+	if slices.Contains(Detect(), "down") {
+		t.Error("Detect() should skip a plugin that reports itself unavailable")
+	}
+}
+
+func TestPluginGrowsKnownBackends(t *testing.T) {
+	installPlugin(t, "wrap", fakePluginScript)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	found := false
+	for _, name := range Detect() {
+		if name == "wrap" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Detect() should include the wrap plugin")
+	}
+	if err := SetBackend("wrap"); err != nil {
+		t.Errorf("SetBackend(wrap) = %v, want nil", err)
+	}
+	b, err := newBackend("wrap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := b.(*Plugin); !ok {
+		t.Errorf("newBackend(wrap) = %T, want *Plugin", b)
+	}
+}
+
+func TestPluginProtocol(t *testing.T) {
+	path := installPlugin(t, "wrap", fakePluginScript)
+	p := NewPlugin("wrap", path)
+
+	if p.SessionEnvVar() != "WRAP_SESSION" {
+		t.Errorf("SessionEnvVar() = %q", p.SessionEnvVar())
+	}
+	t.Setenv("WRAP_SESSION", "api")
+	if !p.InSession() || p.CurrentSessionName() != "api" {
+		t.Error("expected plugin to report the current session from its env var")
+	}
+	if ok, err := p.Available(); !ok || err != nil {
+		t.Errorf("Available() = %v, %v", ok, err)
+	}
+	if ver, _ := p.Version(); ver != "2.1" {
+		t.Errorf("Version() = %q", ver)
+	}
+	sessions, err := p.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Name != "api" || !sessions[0].Active {
+		t.Errorf("List() = %+v", sessions)
+	}
+	if got := p.AttachCommand("api", ""); got != "wrap attach api" {
+		t.Errorf("AttachCommand() = %q", got)
+	}
+	if got := p.DetachCommand(); got != "wrap detach" {
+		t.Errorf("DetachCommand() = %q", got)
+	}
+	if err := p.Kill("api"); err == nil || !strings.Contains(err.Error(), "session is locked") {
+		t.Errorf("Kill() error = %v, want plugin error", err)
+	}
+}
+
+func TestPluginRejectsWrongProtocol(t *testing.T) {
+	path := installPlugin(t, "old", "#!/bin/sh\necho '{\"protocol\":0,\"sessions\":[]}'\n")
+	p := NewPlugin("old", path)
+
+	if _, err := p.List(); err == nil || !strings.Contains(err.Error(), "protocol") {
+		t.Errorf("List() error = %v, want protocol mismatch", err)
+	}
+	if got := p.AttachCommand("x", ""); !strings.HasPrefix(got, "echo ") {
+		t.Errorf("AttachCommand() on failure = %q, want an error-reporting command", got)
+	}
+}
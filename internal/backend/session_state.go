@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/nerveband/zpick/internal/config"
 	"golang.org/x/term"
 )
 
@@ -27,8 +28,11 @@ func InAnySession() bool {
 }
 
 // ShouldAutostart reports whether zpick should launch the picker for the
-// current shell startup.
+// current shell startup. Setting autostart.enabled = false turns it off.
 func ShouldAutostart() bool {
+	if c, err := config.Load(); err == nil && !c.Autostart.Enabled {
+		return false
+	}
 	ttyReady := term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
 	if !ttyReady {
 		ttyReady = hasControllingTTY()
@@ -73,9 +73,9 @@ func (s *Shpool) Attach(name string) error {
 func (s *Shpool) DetachCommand() string { return backend.ShellCommand("shpool") + " detach" }
 
 func (s *Shpool) AttachCommand(name, dir string) string {
-	cmd := fmt.Sprintf(`%s attach "%s"`, backend.ShellCommand("shpool"), name)
+	cmd := fmt.Sprintf("%s attach %s", backend.ShellCommand("shpool"), backend.ShellQuote(name))
 	if dir != "" {
-		return fmt.Sprintf(`cd "%s" && %s`, dir, cmd)
+		return fmt.Sprintf("cd %s && %s", backend.ShellQuote(dir), cmd)
 	}
 	return cmd
 }
@@ -84,6 +84,10 @@ func (s *Shpool) Kill(name string) error {
 	return backend.Command("shpool", "kill", name).Run()
 }
 
+func (s *Shpool) DetachClients(name string) error {
+	return backend.Command("shpool", "detach", name).Run()
+}
+
 // parseShpoolSessions parses the output of shpool list.
 // Each line is a session name.
 func parseShpoolSessions(output string) []backend.Session {
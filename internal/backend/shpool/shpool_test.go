@@ -6,7 +6,18 @@ import (
 	"github.com/nerveband/zpick/internal/backend"
 )
 
-var _ backend.Backend = (*Shpool)(nil)
+var (
+	_ backend.Backend  = (*Shpool)(nil)
+	_ backend.Detacher = (*Shpool)(nil)
+)
+
+func TestShpoolHasNoOtherOptionalCapabilities(t *testing.T) {
+	b := New()
+	if backend.Supports[backend.Renamer](b) || backend.Supports[backend.Previewer](b) ||
+		backend.Supports[backend.DetachedCreator](b) || backend.Supports[backend.WindowLister](b) {
+		t.Error("shpool should not advertise optional capabilities")
+	}
+}
 
 func TestShpoolName(t *testing.T) {
 	b := New()
@@ -57,7 +68,7 @@ func TestShpoolDetachCommand(t *testing.T) {
 func TestShpoolAttachCommand(t *testing.T) {
 	b := New()
 	cmd := b.AttachCommand("my-session", "")
-	want := `shpool attach "my-session"`
+	want := `shpool attach 'my-session'`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -66,7 +77,7 @@ func TestShpoolAttachCommand(t *testing.T) {
 func TestShpoolAttachCommandWithDir(t *testing.T) {
 	b := New()
 	cmd := b.AttachCommand("my-session", "/tmp/foo")
-	want := `cd "/tmp/foo" && shpool attach "my-session"`
+	want := `cd '/tmp/foo' && shpool attach 'my-session'`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
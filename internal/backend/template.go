@@ -0,0 +1,136 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nerveband/zpick/internal/config"
+)
+
+// Template describes the windows (tabs) and panes of a new session. Templates
+// are JSON files in the templates/ folder of the config dir:
+//
+//	{"windows": [
+//	  {"name": "editor", "panes": [{"command": "nvim"}]},
+//	  {"name": "server", "split": "down", "panes": [
+//	    {"command": "make dev"},
+//	    {"dir": "log", "command": "tail -f dev.log"}
+//	  ]}
+//	]}
+type Template struct {
+	Name    string           `json:"-"`
+	Windows []TemplateWindow `json:"windows"`
+}
+
+// TemplateWindow is one window (tmux) or tab (zellij).
+type TemplateWindow struct {
+	Name  string         `json:"name,omitempty"`
+	Dir   string         `json:"dir,omitempty"`   // relative to the session dir
+	Split string         `json:"split,omitempty"` // "right" (default) or "down"
+	Panes []TemplatePane `json:"panes,omitempty"` // empty means a single shell
+}
+
+// TemplatePane is one pane; Command runs in the pane's shell.
+type TemplatePane struct {
+	Dir     string `json:"dir,omitempty"` // relative to the window dir
+	Command string `json:"command,omitempty"`
+}
+
+const (
+	SplitRight = "right"
+	SplitDown  = "down"
+)
+
+// TemplateDir returns the directory holding layout templates.
+func TemplateDir() string {
+	return filepath.Join(config.Dir(), "templates")
+}
+
+// ListTemplates returns the names of the available templates, sorted.
+func ListTemplates() []string {
+	matches, _ := filepath.Glob(filepath.Join(TemplateDir(), "*.json"))
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		names = append(names, strings.TrimSuffix(filepath.Base(m), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadTemplate reads and validates the named template.
+func LoadTemplate(name string) (*Template, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("invalid template name %q", name)
+	}
+	path := filepath.Join(TemplateDir(), name+".json")
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("template %q not found in %s", name, TemplateDir())
+	}
+	if err != nil {
+		return nil, err
+	}
+	t, err := ParseTemplate(data)
+	if err != nil {
+		return nil, fmt.Errorf("template %q: %w", name, err)
+	}
+	t.Name = name
+	return t, nil
+}
+
+// ParseTemplate decodes and validates a template.
+func ParseTemplate(data []byte) (*Template, error) {
+	var t Template
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	if len(t.Windows) == 0 {
+		return nil, fmt.Errorf("no windows")
+	}
+	for i, w := range t.Windows {
+		switch w.Split {
+		case "", SplitRight, SplitDown:
+		default:
+			return nil, fmt.Errorf("window %d: split must be %q or %q", i+1, SplitRight, SplitDown)
+		}
+	}
+	return &t, nil
+}
+
+// Resolved returns a copy of t with every window and pane dir made absolute
+// against base, and every window given at least one pane.
+func (t *Template) Resolved(base string) *Template {
+	out := &Template{Name: t.Name}
+	for _, w := range t.Windows {
+		w.Dir = resolveDir(base, w.Dir)
+		panes := w.Panes
+		if len(panes) == 0 {
+			panes = []TemplatePane{{}}
+		}
+		w.Panes = make([]TemplatePane, len(panes))
+		for i, p := range panes {
+			p.Dir = resolveDir(w.Dir, p.Dir)
+			w.Panes[i] = p
+		}
+		out.Windows = append(out.Windows, w)
+	}
+	return out
+}
+
+func resolveDir(base, dir string) string {
+	switch {
+	case dir == "":
+		return base
+	case dir == "~" || strings.HasPrefix(dir, "~/"):
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, dir[1:])
+	case filepath.IsAbs(dir) || base == "":
+		return dir
+	default:
+		return filepath.Join(base, dir)
+	}
+}
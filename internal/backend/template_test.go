@@ -0,0 +1,87 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate([]byte(`{"windows": [
+		{"name": "editor", "panes": [{"command": "nvim"}]},
+		{"name": "server", "split": "down", "panes": [{"command": "make dev"}, {"dir": "log"}]}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tmpl.Windows) != 2 || tmpl.Windows[1].Split != SplitDown || len(tmpl.Windows[1].Panes) != 2 {
+		t.Errorf("ParseTemplate() = %+v", tmpl)
+	}
+}
+
+func TestParseTemplateRejectsInvalid(t *testing.T) {
+	for _, data := range []string{
+		`{"windows": []}`,
+		`{"windows": [{"split": "sideways"}]}`,
+		`not json`,
+	} {
+		if _, err := ParseTemplate([]byte(data)); err == nil {
+			t.Errorf("ParseTemplate(%s) should fail", data)
+		}
+	}
+}
+
+func TestTemplateResolved(t *testing.T) {
+	tmpl := &Template{Windows: []TemplateWindow{
+		{Name: "editor"},
+		{Dir: "web", Panes: []TemplatePane{{Command: "npm start"}, {Dir: "/var/log"}}},
+	}}
+	got := tmpl.Resolved("/src/app")
+	want := &Template{Windows: []TemplateWindow{
+		{Name: "editor", Dir: "/src/app", Panes: []TemplatePane{{Dir: "/src/app"}}},
+		{Dir: "/src/app/web", Panes: []TemplatePane{
+			{Dir: "/src/app/web", Command: "npm start"},
+			{Dir: "/var/log"},
+		}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Resolved() = %+v, want %+v", got, want)
+	}
+	if len(tmpl.Windows[0].Panes) != 0 {
+		t.Error("Resolved() must not modify the original template")
+	}
+}
+
+func TestListAndLoadTemplates(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := os.MkdirAll(TemplateDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{
+		"dev.json":   `{"windows": [{"name": "editor"}]}`,
+		"ops.json":   `{"windows": [{"name": "logs"}]}`,
+		"README.txt": "not a template",
+	} {
+		if err := os.WriteFile(filepath.Join(TemplateDir(), name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := ListTemplates(); !reflect.DeepEqual(got, []string{"dev", "ops"}) {
+		t.Errorf("ListTemplates() = %v", got)
+	}
+	tmpl, err := LoadTemplate("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Name != "dev" || tmpl.Windows[0].Name != "editor" {
+		t.Errorf("LoadTemplate() = %+v", tmpl)
+	}
+	if _, err := LoadTemplate("missing"); err == nil {
+		t.Error("LoadTemplate(missing) should fail")
+	}
+	if _, err := LoadTemplate("../dev"); err == nil {
+		t.Error("LoadTemplate should reject paths")
+	}
+}
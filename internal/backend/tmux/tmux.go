@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nerveband/zpick/internal/backend"
 )
@@ -58,7 +59,7 @@ func (t *Tmux) Version() (string, error) {
 
 func (t *Tmux) List() ([]backend.Session, error) {
 	out, err := backend.Command("tmux", "list-sessions", "-F",
-		"#{session_name}\t#{session_attached}\t#{pane_current_path}").Output()
+		"#{session_name}\t#{session_attached}\t#{pane_current_path}\t#{session_created}\t#{session_activity}\t#{session_windows}\t#{pane_current_command}").Output()
 	if err != nil {
 		// tmux returns error when server not running (no sessions)
 		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
@@ -87,17 +88,129 @@ func (t *Tmux) DetachCommand() string { return backend.ShellCommand("tmux") + "
 func (t *Tmux) AttachCommand(name, dir string) string {
 	tmux := backend.ShellCommand("tmux")
 	if dir != "" {
-		return fmt.Sprintf(`%s new-session -A -s "%s" -c "%s"`, tmux, name, dir)
+		return fmt.Sprintf("%s new-session -A -s %s -c %s", tmux, backend.ShellQuote(name), backend.ShellQuote(dir))
 	}
-	return fmt.Sprintf(`%s new-session -A -s "%s"`, tmux, name)
+	return fmt.Sprintf("%s new-session -A -s %s", tmux, backend.ShellQuote(name))
 }
 
+// UnsafeNameChars reports '.' and ':', which tmux rewrites to '_' in session
+// names, so the name zp recorded would no longer match. '/' is fine.
+func (t *Tmux) UnsafeNameChars() string { return ".:" }
+
 func (t *Tmux) Kill(name string) error {
 	return backend.Command("tmux", "kill-session", "-t", name).Run()
 }
 
+func (t *Tmux) DetachClients(name string) error {
+	return backend.Command("tmux", "detach-client", "-s", name).Run()
+}
+
+func (t *Tmux) Rename(oldName, newName string) error {
+	return backend.Command("tmux", "rename-session", "-t", oldName, newName).Run()
+}
+
+func (t *Tmux) CreateDetached(name, dir string) error {
+	args := []string{"new-session", "-d", "-s", name}
+	if dir != "" {
+		args = append(args, "-c", dir)
+	}
+	return backend.Command("tmux", args...).Run()
+}
+
+// TemplateCommand builds the session with new-session -d, new-window and
+// split-window, typing each pane's command into its shell, then attaches.
+// An existing session is attached as-is.
+func (t *Tmux) TemplateCommand(name, dir string, tmpl *backend.Template) (string, error) {
+	tmux := backend.ShellCommand("tmux")
+	q := backend.ShellQuote
+	session := q("=" + name)
+	current := q("=" + name + ":")
+
+	var steps []string
+	for i, w := range tmpl.Resolved(dir).Windows {
+		create := fmt.Sprintf("%s new-window -t %s", tmux, current)
+		if i == 0 {
+			create = fmt.Sprintf("%s new-session -d -s %s", tmux, q(name))
+		}
+		if w.Name != "" {
+			create += " -n " + q(w.Name)
+		}
+		for j, p := range w.Panes {
+			switch {
+			case j == 0:
+				steps = append(steps, create+dirFlag(p.Dir))
+			case w.Split == backend.SplitDown:
+				steps = append(steps, fmt.Sprintf("%s split-window -v -t %s%s", tmux, current, dirFlag(p.Dir)))
+			default:
+				steps = append(steps, fmt.Sprintf("%s split-window -h -t %s%s", tmux, current, dirFlag(p.Dir)))
+			}
+			if p.Command != "" {
+				steps = append(steps, fmt.Sprintf("%s send-keys -t %s %s Enter", tmux, current, q(p.Command)))
+			}
+		}
+		if len(w.Panes) > 2 {
+			layout := "even-horizontal"
+			if w.Split == backend.SplitDown {
+				layout = "even-vertical"
+			}
+			steps = append(steps, fmt.Sprintf("%s select-layout -t %s %s", tmux, current, layout))
+		}
+	}
+	steps = append(steps, fmt.Sprintf("%s select-window -t %s", tmux, q("="+name+":^")))
+
+	return fmt.Sprintf("%s has-session -t %s 2>/dev/null || { %s; }; %s attach-session -t %s",
+		tmux, session, strings.Join(steps, " && "), tmux, session), nil
+}
+
+func dirFlag(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	return " -c " + backend.ShellQuote(dir)
+}
+
+func (t *Tmux) Preview(name string, n int) (string, error) {
+	out, err := backend.Command("tmux", "capture-pane", "-p", "-J", "-t", name+":").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run tmux capture-pane: %w", err)
+	}
+	return backend.TailLines(string(out), n), nil
+}
+
+func (t *Tmux) Windows(name string) ([]backend.Window, error) {
+	out, err := backend.Command("tmux", "list-windows", "-t", name, "-F",
+		"#{window_index}\t#{window_name}\t#{window_panes}\t#{window_active}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run tmux list-windows: %w", err)
+	}
+	return parseTmuxWindows(string(out)), nil
+}
+
+// parseTmuxWindows parses the tab-separated output of tmux list-windows.
+// Format: window_index\twindow_name\twindow_panes\twindow_active
+func parseTmuxWindows(output string) []backend.Window {
+	var windows []backend.Window
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) < 4 {
+			continue
+		}
+		w := backend.Window{Name: fields[1], Active: fields[3] == "1"}
+		w.Index, _ = strconv.Atoi(fields[0])
+		w.Panes, _ = strconv.Atoi(fields[2])
+		windows = append(windows, w)
+	}
+	return windows
+}
+
 // parseTmuxSessions parses the tab-separated output of tmux list-sessions.
-// Format: session_name\tsession_attached\tpane_current_path
+// Format: session_name\tsession_attached\tpane_current_path\tsession_created\t
+// session_activity\tsession_windows\tpane_current_command. Trailing fields are
+// optional so older format strings still parse.
 func parseTmuxSessions(output string) []backend.Session {
 	var sessions []backend.Session
 	for _, line := range strings.Split(output, "\n") {
@@ -121,7 +234,28 @@ func parseTmuxSessions(output string) []backend.Session {
 		if len(fields) >= 3 {
 			s.StartedIn = fields[2]
 		}
+		if len(fields) >= 4 {
+			s.CreatedAt = unixSeconds(fields[3])
+		}
+		if len(fields) >= 5 {
+			s.LastActivity = unixSeconds(fields[4])
+		}
+		if len(fields) >= 6 {
+			s.Windows, _ = strconv.Atoi(fields[5])
+		}
+		if len(fields) >= 7 {
+			s.Command = fields[6]
+		}
 		sessions = append(sessions, s)
 	}
 	return sessions
 }
+
+// unixSeconds converts a tmux timestamp (seconds since the epoch) to a time.
+func unixSeconds(v string) time.Time {
+	sec, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || sec <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
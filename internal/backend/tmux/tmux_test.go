@@ -2,12 +2,23 @@ package tmux
 
 import (
 	"testing"
+	"time"
 
 	"github.com/nerveband/zpick/internal/backend"
 )
 
 var _ backend.Backend = (*Tmux)(nil)
 
+// Tmux exposes every optional capability.
+var (
+	_ backend.Renamer         = (*Tmux)(nil)
+	_ backend.DetachedCreator = (*Tmux)(nil)
+	_ backend.Previewer       = (*Tmux)(nil)
+	_ backend.WindowLister    = (*Tmux)(nil)
+	_ backend.Templater       = (*Tmux)(nil)
+	_ backend.Detacher        = (*Tmux)(nil)
+)
+
 func TestTmuxName(t *testing.T) {
 	b := New()
 	if b.Name() != "tmux" {
@@ -48,7 +59,7 @@ func TestTmuxInSessionFalse(t *testing.T) {
 func TestTmuxAttachCommand(t *testing.T) {
 	b := New()
 	cmd := b.AttachCommand("my-session", "")
-	want := `tmux new-session -A -s "my-session"`
+	want := `tmux new-session -A -s 'my-session'`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -57,7 +68,7 @@ func TestTmuxAttachCommand(t *testing.T) {
 func TestTmuxAttachCommandWithDir(t *testing.T) {
 	b := New()
 	cmd := b.AttachCommand("my-session", "/tmp/foo")
-	want := `tmux new-session -A -s "my-session" -c "/tmp/foo"`
+	want := `tmux new-session -A -s 'my-session' -c '/tmp/foo'`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -101,3 +112,63 @@ func TestParseTmuxSessionsEmpty(t *testing.T) {
 		t.Fatalf("expected 0 sessions, got %d", len(sessions))
 	}
 }
+
+func TestParseTmuxWindows(t *testing.T) {
+	output := "0\teditor\t2\t1\n1\tserver\t1\t0\nbad line\n"
+	windows := parseTmuxWindows(output)
+	if len(windows) != 2 {
+		t.Fatalf("expected 2 windows, got %d", len(windows))
+	}
+	want := backend.Window{Index: 0, Name: "editor", Panes: 2, Active: true}
+	if windows[0] != want {
+		t.Errorf("windows[0] = %+v, want %+v", windows[0], want)
+	}
+	if windows[1].Name != "server" || windows[1].Active {
+		t.Errorf("unexpected windows[1] %+v", windows[1])
+	}
+}
+
+func TestParseTmuxSessionsMetadata(t *testing.T) {
+	output := "work\t1\t/home/user/work\t1771652262\t1771655862\t3\tnvim\n"
+	sessions := parseTmuxSessions(output)
+	if len(sessions) != 1 {
+		t.Fatalf("expected 1 session, got %d", len(sessions))
+	}
+	s := sessions[0]
+	if !s.CreatedAt.Equal(time.Unix(1771652262, 0)) {
+		t.Errorf("CreatedAt = %v", s.CreatedAt)
+	}
+	if !s.LastActivity.Equal(time.Unix(1771655862, 0)) {
+		t.Errorf("LastActivity = %v", s.LastActivity)
+	}
+	if s.Windows != 3 {
+		t.Errorf("Windows = %d, want 3", s.Windows)
+	}
+	if s.Command != "nvim" {
+		t.Errorf("Command = %q, want nvim", s.Command)
+	}
+}
+
+func TestTmuxTemplateCommand(t *testing.T) {
+	tmpl := &backend.Template{Windows: []backend.TemplateWindow{
+		{Name: "editor", Panes: []backend.TemplatePane{{Command: "nvim"}}},
+		{Name: "server", Split: backend.SplitDown, Panes: []backend.TemplatePane{
+			{Command: "make dev"},
+			{Dir: "log"},
+		}},
+	}}
+	got, err := New().TemplateCommand("api", "/src/api", tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `tmux has-session -t '=api' 2>/dev/null || { ` +
+		`tmux new-session -d -s 'api' -n 'editor' -c '/src/api' && ` +
+		`tmux send-keys -t '=api:' 'nvim' Enter && ` +
+		`tmux new-window -t '=api:' -n 'server' -c '/src/api' && ` +
+		`tmux send-keys -t '=api:' 'make dev' Enter && ` +
+		`tmux split-window -v -t '=api:' -c '/src/api/log' && ` +
+		`tmux select-window -t '=api:^'; }; tmux attach-session -t '=api'`
+	if got != want {
+		t.Errorf("TemplateCommand() =\n%s\nwant\n%s", got, want)
+	}
+}
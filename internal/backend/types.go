@@ -3,15 +3,27 @@ package backend
 import (
 	"os"
 	"syscall"
+	"time"
 )
 
 // Session represents a session from any backend.
+// Metadata beyond Name is best-effort: backends fill in what they can and
+// leave the rest zero.
 type Session struct {
 	Name      string `json:"name"`
 	PID       int    `json:"pid,omitempty"`
 	Clients   int    `json:"clients"`
 	StartedIn string `json:"started_in"`
 	Active    bool   `json:"active"`
+	Backend   string `json:"backend,omitempty"` // set by the aggregate backend
+	Host      string `json:"host,omitempty"`    // set for sessions on a remote host
+
+	CreatedAt    time.Time `json:"created_at,omitzero"`
+	LastActivity time.Time `json:"last_activity,omitzero"`
+	Windows      int       `json:"windows,omitempty"` // window/tab count
+	Command      string    `json:"command,omitempty"` // foreground command
+	EndedAt      time.Time `json:"ended_at,omitzero"` // when the session's task exited
+	ExitCode     int       `json:"exit_code,omitempty"`
 }
 
 // Backend is the interface that all session managers implement.
@@ -0,0 +1,107 @@
+package zellij
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+// TemplateCommand writes the template as a KDL layout and starts the session
+// with it. An existing session is attached as-is.
+func (z *Zellij) TemplateCommand(name, dir string, tmpl *backend.Template) (string, error) {
+	if sessions, err := z.FastList(); err == nil {
+		for _, s := range sessions {
+			if s.Name == name {
+				return z.AttachCommand(name, ""), nil
+			}
+		}
+	}
+
+	path, err := layoutPath(name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(buildLayout(tmpl.Resolved(dir))), 0644); err != nil {
+		return "", err
+	}
+
+	cmd := fmt.Sprintf("%s --session %s --layout %s",
+		backend.ShellCommand("zellij"), backend.ShellQuote(name), backend.ShellQuote(path))
+	if dir != "" {
+		return fmt.Sprintf("cd %s && %s", backend.ShellQuote(dir), cmd), nil
+	}
+	return cmd, nil
+}
+
+// layoutPath returns where the generated layout for a session is written.
+func layoutPath(name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(home, ".cache", "zpick", "layouts")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".kdl"), nil
+}
+
+// buildLayout renders a resolved template as a zellij KDL layout, keeping
+// the default tab and status bars.
+func buildLayout(t *backend.Template) string {
+	var b strings.Builder
+	b.WriteString("layout {\n")
+	b.WriteString("    default_tab_template {\n")
+	b.WriteString("        pane size=1 borderless=true {\n")
+	b.WriteString("            plugin location=\"zellij:tab-bar\"\n")
+	b.WriteString("        }\n")
+	b.WriteString("        children\n")
+	b.WriteString("        pane size=2 borderless=true {\n")
+	b.WriteString("            plugin location=\"zellij:status-bar\"\n")
+	b.WriteString("        }\n")
+	b.WriteString("    }\n")
+
+	for i, w := range t.Windows {
+		b.WriteString("    tab")
+		if w.Name != "" {
+			fmt.Fprintf(&b, " name=%s", kdlString(w.Name))
+		}
+		if w.Dir != "" {
+			fmt.Fprintf(&b, " cwd=%s", kdlString(w.Dir))
+		}
+		if w.Split == backend.SplitDown {
+			b.WriteString(" split_direction=\"horizontal\"")
+		} else {
+			b.WriteString(" split_direction=\"vertical\"")
+		}
+		if i == 0 {
+			b.WriteString(" focus=true")
+		}
+		b.WriteString(" {\n")
+		for _, p := range w.Panes {
+			b.WriteString("        pane")
+			if p.Dir != "" {
+				fmt.Fprintf(&b, " cwd=%s", kdlString(p.Dir))
+			}
+			if p.Command == "" {
+				b.WriteString("\n")
+				continue
+			}
+			// Run through sh so the pane keeps a shell after the command exits.
+			b.WriteString(" command=\"sh\" {\n")
+			fmt.Fprintf(&b, "            args \"-c\" %s\n", kdlString(p.Command+`; exec "${SHELL:-sh}"`))
+			b.WriteString("        }\n")
+		}
+		b.WriteString("    }\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func kdlString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,34 @@
+package zellij
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+func TestBuildLayout(t *testing.T) {
+	tmpl := &backend.Template{Windows: []backend.TemplateWindow{
+		{Name: "editor", Panes: []backend.TemplatePane{{Command: `nvim "main.go"`}}},
+		{Name: "server", Split: backend.SplitDown, Panes: []backend.TemplatePane{
+			{Command: "make dev"},
+			{Dir: "log"},
+		}},
+	}}
+	got := buildLayout(tmpl.Resolved("/src/api"))
+
+	for _, want := range []string{
+		`tab name="editor" cwd="/src/api" split_direction="vertical" focus=true {`,
+		`args "-c" "nvim \"main.go\"; exec \"${SHELL:-sh}\""`,
+		`tab name="server" cwd="/src/api" split_direction="horizontal" {`,
+		`pane cwd="/src/api/log"` + "\n",
+		`plugin location="zellij:status-bar"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("layout missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "{") != strings.Count(got, "}") {
+		t.Errorf("unbalanced braces:\n%s", got)
+	}
+}
@@ -77,9 +77,9 @@ func (z *Zellij) Attach(name string) error {
 func (z *Zellij) DetachCommand() string { return backend.ShellCommand("zellij") + " action detach" }
 
 func (z *Zellij) AttachCommand(name, dir string) string {
-	cmd := fmt.Sprintf(`%s attach "%s"`, backend.ShellCommand("zellij"), name)
+	cmd := fmt.Sprintf("%s attach %s", backend.ShellCommand("zellij"), backend.ShellQuote(name))
 	if dir != "" {
-		return fmt.Sprintf(`cd "%s" && %s`, dir, cmd)
+		return fmt.Sprintf("cd %s && %s", backend.ShellQuote(dir), cmd)
 	}
 	return cmd
 }
@@ -88,6 +88,57 @@ func (z *Zellij) Kill(name string) error {
 	return backend.Command("zellij", "kill-session", name).Run()
 }
 
+func (z *Zellij) Rename(oldName, newName string) error {
+	return backend.Command("zellij", "--session", oldName, "action", "rename-session", newName).Run()
+}
+
+func (z *Zellij) CreateDetached(name, dir string) error {
+	cmd := backend.Command("zellij", "attach", "--create-background", name)
+	cmd.Dir = dir
+	return cmd.Run()
+}
+
+func (z *Zellij) Preview(name string, n int) (string, error) {
+	f, err := os.CreateTemp("", "zpick-preview-*")
+	if err != nil {
+		return "", err
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+
+	if err := backend.Command("zellij", "--session", name, "action", "dump-screen", path).Run(); err != nil {
+		return "", fmt.Errorf("failed to run zellij dump-screen: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return backend.TailLines(string(data), n), nil
+}
+
+func (z *Zellij) Windows(name string) ([]backend.Window, error) {
+	out, err := backend.Command("zellij", "--session", name, "action", "query-tab-names").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run zellij query-tab-names: %w", err)
+	}
+	return parseTabNames(string(out)), nil
+}
+
+// parseTabNames parses the output of zellij action query-tab-names.
+// Each line is a tab name, in tab order.
+func parseTabNames(output string) []backend.Window {
+	var windows []backend.Window
+	for _, line := range strings.Split(output, "\n") {
+		name := strings.TrimSpace(line)
+		if name == "" {
+			continue
+		}
+		windows = append(windows, backend.Window{Index: len(windows) + 1, Name: name})
+	}
+	return windows
+}
+
 // parseSessions parses the output of zellij list-sessions.
 // Output format varies by version. With --short --no-formatting, each line is a session name.
 // Without those flags, lines may include status like "(current session)" or "EXITED".
@@ -9,6 +9,15 @@ import (
 // Verify Zellij implements the Backend interface.
 var _ backend.Backend = (*Zellij)(nil)
 
+// Zellij exposes every optional capability.
+var (
+	_ backend.Renamer         = (*Zellij)(nil)
+	_ backend.DetachedCreator = (*Zellij)(nil)
+	_ backend.Previewer       = (*Zellij)(nil)
+	_ backend.WindowLister    = (*Zellij)(nil)
+	_ backend.Templater       = (*Zellij)(nil)
+)
+
 func TestZellijName(t *testing.T) {
 	b := New()
 	if b.Name() != "zellij" {
@@ -49,7 +58,7 @@ func TestZellijInSessionFalse(t *testing.T) {
 func TestZellijAttachCommand(t *testing.T) {
 	b := New()
 	cmd := b.AttachCommand("my-session", "")
-	want := `zellij attach "my-session"`
+	want := `zellij attach 'my-session'`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -58,7 +67,7 @@ func TestZellijAttachCommand(t *testing.T) {
 func TestZellijAttachCommandWithDir(t *testing.T) {
 	b := New()
 	cmd := b.AttachCommand("my-session", "/tmp/foo")
-	want := `cd "/tmp/foo" && zellij attach "my-session"`
+	want := `cd '/tmp/foo' && zellij attach 'my-session'`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -131,3 +140,16 @@ func TestParseSessionsNoActive(t *testing.T) {
 		t.Fatalf("expected 0 sessions, got %d", len(sessions))
 	}
 }
+
+func TestParseTabNames(t *testing.T) {
+	windows := parseTabNames("editor\nserver\n\nlogs\n")
+	if len(windows) != 3 {
+		t.Fatalf("expected 3 tabs, got %d", len(windows))
+	}
+	if windows[0].Index != 1 || windows[0].Name != "editor" {
+		t.Errorf("unexpected first tab %+v", windows[0])
+	}
+	if windows[2].Index != 3 || windows[2].Name != "logs" {
+		t.Errorf("unexpected last tab %+v", windows[2])
+	}
+}
@@ -3,12 +3,14 @@ package zmosh
 import (
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nerveband/zpick/internal/backend"
 )
 
 // ParseSessions parses the tab-separated output of `zmosh list`.
-// Each line has fields like: session_name=foo\tpid=123\tclients=1\tstarted_in=~/bar
+// Each line has fields like: session_name=foo\tpid=123\tclients=1\tcreated_at=<ns>\t
+// task_ended_at=<ns>\ttask_exit_code=0\tstarted_in=~/bar
 // Lines may have leading whitespace or a → prefix for the current session.
 func ParseSessions(output string) []backend.Session {
 	var sessions []backend.Session
@@ -35,6 +37,12 @@ func ParseSessions(output string) []backend.Session {
 					s.Clients, _ = strconv.Atoi(v)
 				case "started_in":
 					s.StartedIn = v
+				case "created_at":
+					s.CreatedAt = unixTime(v)
+				case "task_ended_at":
+					s.EndedAt = unixTime(v)
+				case "task_exit_code":
+					s.ExitCode, _ = strconv.Atoi(v)
 				}
 			}
 		}
@@ -48,3 +56,16 @@ func ParseSessions(output string) []backend.Session {
 
 	return sessions
 }
+
+// unixTime parses a zmx timestamp. zmx reports nanoseconds; plain seconds are
+// accepted too. Zero means "not set".
+func unixTime(v string) time.Time {
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}
+	}
+	if n < 1e12 {
+		return time.Unix(n, 0)
+	}
+	return time.Unix(0, n)
+}
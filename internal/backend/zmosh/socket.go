@@ -48,7 +48,10 @@ func ResolveZmxDir() (string, error) {
 	return "", fmt.Errorf("could not resolve zmx socket directory")
 }
 
-// FastListDir reads session names directly from socket files in the zmx directory.
+// FastListDir reads session names directly from socket files in the zmx
+// directory. Sockets carry no metadata: the picker fills in creation times
+// from zp's history, and whether a session's task has exited is only known
+// to the slower `zmosh list`.
 func FastListDir(dir string) ([]backend.Session, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
@@ -67,6 +70,8 @@ func FastListDir(dir string) ([]backend.Session, error) {
 		if info.Mode().Type()&os.ModeSocket == 0 {
 			continue
 		}
+		// The socket's mtime changes whenever it's touched or recreated, so
+		// it isn't a start time.
 		sessions = append(sessions, backend.Session{
 			Name:      e.Name(),
 			StartedIn: "~",
@@ -94,13 +94,13 @@ func (z *Zmosh) AttachCommand(name, dir string) string {
 	zmosh := backend.ShellCommand("zmosh")
 	var attachCmd string
 	if enabled && host != "" {
-		attachCmd = fmt.Sprintf(`%s attach -r %s "%s"`, zmosh, host, name)
+		attachCmd = fmt.Sprintf(`%s attach -r %s %s`, zmosh, backend.ShellQuote(host), backend.ShellQuote(name))
 	} else {
-		attachCmd = fmt.Sprintf(`%s attach "%s"`, zmosh, name)
+		attachCmd = fmt.Sprintf(`%s attach %s`, zmosh, backend.ShellQuote(name))
 	}
 
 	if dir != "" {
-		return fmt.Sprintf(`cd "%s" && %s`, dir, attachCmd)
+		return fmt.Sprintf("cd %s && %s", backend.ShellQuote(dir), attachCmd)
 	}
 	return attachCmd
 }
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/nerveband/zpick/internal/backend"
 )
@@ -52,7 +53,7 @@ func TestZmoshInSessionFalse(t *testing.T) {
 func TestZmoshAttachCommand(t *testing.T) {
 	b := New()
 	cmd := b.AttachCommand("my-session", "")
-	want := `zmosh attach "my-session"`
+	want := `zmosh attach 'my-session'`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -61,7 +62,7 @@ func TestZmoshAttachCommand(t *testing.T) {
 func TestZmoshAttachCommandWithDir(t *testing.T) {
 	b := New()
 	cmd := b.AttachCommand("my-session", "/tmp/foo")
-	want := `cd "/tmp/foo" && zmosh attach "my-session"`
+	want := `cd '/tmp/foo' && zmosh attach 'my-session'`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -74,7 +75,7 @@ func TestZmoshAttachCommandUDP(t *testing.T) {
 
 	b := New()
 	cmd := b.AttachCommand("my-session", "")
-	want := `zmosh attach -r myhost "my-session"`
+	want := `zmosh attach -r 'myhost' 'my-session'`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -88,7 +89,7 @@ func TestZmoshAttachCommandUDPNoHost(t *testing.T) {
 	b := New()
 	cmd := b.AttachCommand("my-session", "")
 	// No host set — no -r flag
-	want := `zmosh attach "my-session"`
+	want := `zmosh attach 'my-session'`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -123,6 +124,28 @@ func TestParseSessions(t *testing.T) {
 	}
 }
 
+func TestParseSessionsMetadata(t *testing.T) {
+	input := "session_name=build\tpid=42\tclients=0\tcreated_at=1771652262707138000\ttask_ended_at=1771652300000000000\ttask_exit_code=2\tstarted_in=~/src\n" +
+		"session_name=live\tpid=43\tclients=1\tcreated_at=1771642928511196000\ttask_ended_at=0\ttask_exit_code=0\tstarted_in=~/src\n"
+
+	sessions := ParseSessions(input)
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	if !sessions[0].CreatedAt.Equal(time.Unix(0, 1771652262707138000)) {
+		t.Errorf("CreatedAt = %v", sessions[0].CreatedAt)
+	}
+	if !sessions[0].EndedAt.Equal(time.Unix(0, 1771652300000000000)) {
+		t.Errorf("EndedAt = %v", sessions[0].EndedAt)
+	}
+	if sessions[0].ExitCode != 2 {
+		t.Errorf("ExitCode = %d, want 2", sessions[0].ExitCode)
+	}
+	if !sessions[1].EndedAt.IsZero() {
+		t.Errorf("running session should have zero EndedAt, got %v", sessions[1].EndedAt)
+	}
+}
+
 func TestParseEmpty(t *testing.T) {
 	sessions := ParseSessions("")
 	if len(sessions) != 0 {
@@ -171,6 +194,9 @@ func TestFastListDir(t *testing.T) {
 		if s.StartedIn != "~" {
 			t.Errorf("expected StartedIn=~, got %q", s.StartedIn)
 		}
+		if !s.CreatedAt.IsZero() {
+			t.Errorf("CreatedAt = %v, want zero: a socket's mtime isn't its start time", s.CreatedAt)
+		}
 	}
 	if !names["work"] || !names["play"] {
 		t.Errorf("expected work and play sessions, got %v", names)
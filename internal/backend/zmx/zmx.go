@@ -88,9 +88,9 @@ func (z *Zmx) Attach(name string) error {
 func (z *Zmx) DetachCommand() string { return backend.ShellCommand("zmx") + " detach" }
 
 func (z *Zmx) AttachCommand(name, dir string) string {
-	cmd := fmt.Sprintf(`%s attach "%s"`, backend.ShellCommand("zmx"), name)
+	cmd := fmt.Sprintf("%s attach %s", backend.ShellCommand("zmx"), backend.ShellQuote(name))
 	if dir != "" {
-		return fmt.Sprintf(`cd "%s" && %s`, dir, cmd)
+		return fmt.Sprintf("cd %s && %s", backend.ShellQuote(dir), cmd)
 	}
 	return cmd
 }
@@ -49,7 +49,7 @@ func TestZmxDetachCommand(t *testing.T) {
 func TestZmxAttachCommand(t *testing.T) {
 	b := New()
 	cmd := b.AttachCommand("my-session", "")
-	want := `zmx attach "my-session"`
+	want := `zmx attach 'my-session'`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
@@ -58,7 +58,7 @@ func TestZmxAttachCommand(t *testing.T) {
 func TestZmxAttachCommandWithDir(t *testing.T) {
 	b := New()
 	cmd := b.AttachCommand("my-session", "/tmp/foo")
-	want := `cd "/tmp/foo" && zmx attach "my-session"`
+	want := `cd '/tmp/foo' && zmx attach 'my-session'`
 	if cmd != want {
 		t.Errorf("AttachCommand() = %q, want %q", cmd, want)
 	}
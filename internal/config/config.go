@@ -0,0 +1,437 @@
+// Package config loads and saves zpick's single config file, config.toml.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FileName is the name of the config file inside Dir().
+const FileName = "config.toml"
+
+// SortOrders are the valid sort.order values, in the order the picker
+// cycles through them. "default" keeps the backend's own order.
+var SortOrders = []string{"default", "name", "recent", "activity", "created", "attached-first", "dir"}
+
+// ThemeNames are the valid theme.name values. "auto" is the default theme,
+// or mono when NO_COLOR is set.
+var ThemeNames = []string{"auto", "default", "light", "high-contrast", "mono"}
+
+// NamePlaceholders are the {placeholders} naming templates may use. {date}
+// also takes a layout, as in {date:0102}.
+var NamePlaceholders = []string{"dir", "repo", "branch", "parent", "date", "host", "n"}
+
+// DefaultGuardApps are the apps guarded when the config doesn't say otherwise.
+var DefaultGuardApps = []string{"claude", "codex", "opencode"}
+
+// Config is the typed contents of config.toml. Each field is a [section];
+// the toml tags give the section and key names.
+type Config struct {
+	Backend   BackendConfig   `toml:"backend"`
+	Keys      KeysConfig      `toml:"keys"`
+	UDP       UDPConfig       `toml:"udp"`
+	Guard     GuardConfig     `toml:"guard"`
+	Naming    NamingConfig    `toml:"naming"`
+	Autostart AutostartConfig `toml:"autostart"`
+	Remote    RemoteConfig    `toml:"remote"`
+	Preview   PreviewConfig   `toml:"preview"`
+	Sort      SortConfig      `toml:"sort"`
+	Group     GroupConfig     `toml:"group"`
+	Theme     ThemeConfig     `toml:"theme"`
+	Pin       PinConfig       `toml:"pin"`
+	Git       GitConfig       `toml:"git"`
+}
+
+// BackendConfig selects the session manager.
+type BackendConfig struct {
+	Name string `toml:"name"` // backend name, "all", or empty to auto-detect
+}
+
+// KeysConfig controls session key labels.
+type KeysConfig struct {
+	Mode string `toml:"mode"` // "numbers" or "letters"
+}
+
+// UDPConfig controls zmosh's UDP remote mode.
+type UDPConfig struct {
+	Enabled bool   `toml:"enabled"`
+	Host    string `toml:"host"`
+}
+
+// GuardConfig lists the commands wrapped by the session guard.
+type GuardConfig struct {
+	Apps []string `toml:"apps"`
+}
+
+// NamingConfig controls generated session names.
+type NamingConfig struct {
+	DateFormat string `toml:"date_format"` // Go time layout for {date}
+	New        string `toml:"new"`         // template for Enter
+	Date       string `toml:"date"`        // template for 'd'
+	Zoxide     string `toml:"zoxide"`      // template for 'z'
+}
+
+// AutostartConfig controls whether new shells open the picker.
+type AutostartConfig struct {
+	Enabled bool `toml:"enabled"`
+}
+
+// RemoteConfig lists SSH hosts whose sessions the picker also shows.
+type RemoteConfig struct {
+	Hosts []string `toml:"hosts"` // ssh destinations, e.g. "devbox" or "me@vm1"
+}
+
+// PreviewConfig controls the session preview pane.
+type PreviewConfig struct {
+	Auto     bool `toml:"auto"`      // show the pane when the terminal is wide enough
+	MinWidth int  `toml:"min_width"` // columns needed for the automatic pane
+}
+
+// SortConfig controls the order of the session list.
+type SortConfig struct {
+	Order string `toml:"order"` // one of SortOrders
+}
+
+// GroupConfig controls grouping sessions under their repository or project.
+type GroupConfig struct {
+	Enabled bool     `toml:"enabled"`
+	Roots   []string `toml:"roots"` // project roots, e.g. "~/src"; each child is a group
+}
+
+// ThemeConfig picks the TUI colors.
+type ThemeConfig struct {
+	Name    string   `toml:"name"`    // one of ThemeNames
+	Palette []string `toml:"palette"` // overrides, e.g. "bold_white=1;34"
+}
+
+// PinConfig lists the sessions kept at the top of the picker.
+type PinConfig struct {
+	Sessions []string `toml:"sessions"` // session names; the first gets key 1
+}
+
+// GitConfig controls what the picker shows about sessions' repositories.
+type GitConfig struct {
+	Status bool `toml:"status"` // show the branch and a dirty marker by each session
+}
+
+// Default returns the configuration used for any key the file doesn't set.
+func Default() Config {
+	return Config{
+		Keys:      KeysConfig{Mode: "numbers"},
+		UDP:       UDPConfig{Enabled: true},
+		Guard:     GuardConfig{Apps: append([]string{}, DefaultGuardApps...)},
+		Naming:    NamingConfig{DateFormat: "0102", New: "{repo}/{branch}", Date: "{repo}-{date}", Zoxide: "{repo}/{branch}"},
+		Autostart: AutostartConfig{Enabled: true},
+		Remote:    RemoteConfig{Hosts: []string{}},
+		Preview:   PreviewConfig{Auto: true, MinWidth: 110},
+		Sort:      SortConfig{Order: "default"},
+		Group:     GroupConfig{Roots: []string{}},
+		Theme:     ThemeConfig{Name: "auto", Palette: []string{}},
+		Pin:       PinConfig{Sessions: []string{}},
+		Git:       GitConfig{Status: true},
+	}
+}
+
+// Dir returns the zpick config directory, respecting XDG_CONFIG_HOME.
+func Dir() string {
+	if d := os.Getenv("XDG_CONFIG_HOME"); d != "" {
+		return filepath.Join(d, "zpick")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "zpick")
+}
+
+// Path returns the path to config.toml.
+func Path() string {
+	return filepath.Join(Dir(), FileName)
+}
+
+// Load reads config.toml on top of the defaults. If the file doesn't exist
+// yet, settings are migrated from the legacy per-setting files and, when any
+// were found, written out as config.toml. Settings with invalid values are
+// reported by key and left at their defaults.
+func Load() (Config, error) {
+	c, err := load()
+	if err != nil {
+		return c, err
+	}
+	if err := c.validate(); err != nil {
+		return c, fmt.Errorf("%s: %w", Path(), err)
+	}
+	return c, nil
+}
+
+// load is Load without validation, for Update: a bad value elsewhere in the
+// file mustn't stop one key from being set, or fixed.
+func load() (Config, error) {
+	c := Default()
+	data, err := os.ReadFile(Path())
+	if os.IsNotExist(err) {
+		if migrateLegacy(&c) {
+			if err := Save(c); err != nil {
+				return c, fmt.Errorf("migrating legacy config: %w", err)
+			}
+		}
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	if err := decode(string(data), &c); err != nil {
+		return Default(), fmt.Errorf("%s: %w", Path(), err)
+	}
+	return c, nil
+}
+
+// Save writes c to config.toml, creating the config dir as needed. It
+// writes a temporary file and renames it into place, so a crash or a
+// concurrent zp never leaves a half-written config. When config.toml is a
+// symlink, the file it points to is replaced.
+func Save(c Config) error {
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		return err
+	}
+	path := Path()
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), FileName+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.WriteString(encode(c)); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Update loads the config, applies fn, and saves the result. Invalid
+// settings fn doesn't touch are saved as they were.
+func Update(fn func(*Config) error) error {
+	c, err := load()
+	if err != nil {
+		return err
+	}
+	if err := fn(&c); err != nil {
+		return err
+	}
+	return Save(c)
+}
+
+// Keys returns every settable key as "section.key", in file order.
+func Keys() []string {
+	var keys []string
+	forEachField(reflect.ValueOf(&Config{}).Elem(), func(key string, _ reflect.Value) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+// Get returns the value of a "section.key" setting formatted for display.
+func Get(key string) (string, error) {
+	c, err := Load()
+	if err != nil {
+		return "", err
+	}
+	v, ok := lookup(reflect.ValueOf(&c).Elem(), key)
+	if !ok {
+		return "", unknownKey(key)
+	}
+	if v.Kind() == reflect.String {
+		return v.String(), nil
+	}
+	return formatValue(v), nil
+}
+
+// Set parses value for a "section.key" setting and saves it. Lists accept
+// either a TOML array or a comma-separated string.
+func Set(key, value string) error {
+	return Update(func(c *Config) error {
+		v, ok := lookup(reflect.ValueOf(c).Elem(), key)
+		if !ok {
+			return unknownKey(key)
+		}
+		if err := setFromString(v, value); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		return c.check(key)
+	})
+}
+
+// checks validate the settings whose wrong values would otherwise be
+// silently ignored, by key.
+var checks = []struct {
+	key   string
+	check func(c *Config) error
+}{
+	{"keys.mode", func(c *Config) error {
+		if c.Keys.Mode != "numbers" && c.Keys.Mode != "letters" {
+			return fmt.Errorf("invalid key mode %q (valid: numbers, letters)", c.Keys.Mode)
+		}
+		return nil
+	}},
+	{"naming.new", func(c *Config) error { return checkTemplate(c.Naming.New) }},
+	{"naming.date", func(c *Config) error { return checkTemplate(c.Naming.Date) }},
+	{"naming.zoxide", func(c *Config) error { return checkTemplate(c.Naming.Zoxide) }},
+	{"sort.order", func(c *Config) error {
+		if !slices.Contains(SortOrders, c.Sort.Order) {
+			return fmt.Errorf("invalid sort order %q (valid: %s)", c.Sort.Order, strings.Join(SortOrders, ", "))
+		}
+		return nil
+	}},
+	{"theme.name", func(c *Config) error {
+		if !slices.Contains(ThemeNames, c.Theme.Name) {
+			return fmt.Errorf("invalid theme %q (valid: %s)", c.Theme.Name, strings.Join(ThemeNames, ", "))
+		}
+		return nil
+	}},
+}
+
+// check validates the setting key, if it has a check.
+func (c *Config) check(key string) error {
+	for _, ck := range checks {
+		if ck.key == key {
+			if err := ck.check(c); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validate checks every setting, reporting each invalid one by key and
+// putting it back to its default.
+func (c *Config) validate() error {
+	d := Default()
+	var errs []error
+	for _, ck := range checks {
+		if err := c.check(ck.key); err != nil {
+			errs = append(errs, err)
+			v, _ := lookup(reflect.ValueOf(c).Elem(), ck.key)
+			dv, _ := lookup(reflect.ValueOf(&d).Elem(), ck.key)
+			v.Set(dv)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkTemplate rejects naming templates with unknown placeholders.
+func checkTemplate(tmpl string) error {
+	rest := tmpl
+	for {
+		_, after, ok := strings.Cut(rest, "{")
+		if !ok {
+			return nil
+		}
+		field, tail, ok := strings.Cut(after, "}")
+		if !ok {
+			return fmt.Errorf("naming template %q: unclosed {", tmpl)
+		}
+		name, _, _ := strings.Cut(field, ":")
+		if !slices.Contains(NamePlaceholders, name) {
+			return fmt.Errorf("naming template %q: unknown placeholder {%s} (valid: %s)", tmpl, field, strings.Join(NamePlaceholders, ", "))
+		}
+		rest = tail
+	}
+}
+
+func unknownKey(key string) error {
+	return fmt.Errorf("unknown config key %q (valid: %s)", key, strings.Join(Keys(), ", "))
+}
+
+// lookup finds the field for "section.key" (or a top-level "key") in a
+// struct value.
+func lookup(root reflect.Value, key string) (reflect.Value, bool) {
+	section, name, ok := strings.Cut(key, ".")
+	if !ok {
+		f, ok := fieldByTag(root, key)
+		if !ok || f.Kind() == reflect.Struct || f.Kind() == reflect.Map {
+			return reflect.Value{}, false
+		}
+		return f, true
+	}
+	sv, ok := fieldByTag(root, section)
+	if !ok || sv.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	return fieldByTag(sv, name)
+}
+
+// fieldByTag returns the field of struct value v whose toml tag is tag.
+func fieldByTag(v reflect.Value, tag string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("toml") == tag {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// forEachField walks the sections of a Config value in declaration order.
+func forEachField(root reflect.Value, fn func(key string, v reflect.Value)) {
+	rt := root.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		section := rt.Field(i).Tag.Get("toml")
+		sv := root.Field(i)
+		st := sv.Type()
+		for j := 0; j < st.NumField(); j++ {
+			fn(section+"."+st.Field(j).Tag.Get("toml"), sv.Field(j))
+		}
+	}
+}
+
+func setFromString(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("expected true or false, got %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("expected a number, got %q", s)
+		}
+		v.SetInt(int64(n))
+	case reflect.Slice:
+		var list []string
+		if strings.HasPrefix(strings.TrimSpace(s), "[") {
+			parsed, err := parseArray(strings.TrimSpace(s))
+			if err != nil {
+				return err
+			}
+			list = parsed
+		} else {
+			for _, item := range strings.Split(s, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	if got, want := Dir(), filepath.Join(dir, "zpick"); got != want {
+		t.Errorf("Dir() = %q, want %q", got, want)
+	}
+	if got, want := Path(), filepath.Join(dir, "zpick", "config.toml"); got != want {
+		t.Errorf("Path() = %q, want %q", got, want)
+	}
+}
+
+func TestLoadDefaultsWithoutFiles(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c, Default()) {
+		t.Errorf("Load() = %+v, want defaults", c)
+	}
+	if _, err := os.Stat(Path()); !os.IsNotExist(err) {
+		t.Error("Load() should not create config.toml when there is nothing to migrate")
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	want := Default()
+	want.Backend.Name = "tmux"
+	want.Keys.Mode = "letters"
+	want.UDP = UDPConfig{Enabled: false, Host: "my \"host\""}
+	want.Guard.Apps = []string{"claude", "aider"}
+	want.Autostart.Enabled = false
+
+	if err := Save(want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+}
+
+func TestSaveReplacesAtomically(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// A symlinked config (say, from a dotfiles repo) stays a symlink.
+	target := filepath.Join(t.TempDir(), "config.toml")
+	os.WriteFile(target, nil, 0o644)
+	os.MkdirAll(Dir(), 0o755)
+	if err := os.Symlink(target, Path()); err != nil {
+		t.Fatal(err)
+	}
+	c := Default()
+	c.Sort.Order = "name"
+	if err := Save(c); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Lstat(Path()); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("config.toml is no longer a symlink (%v)", err)
+	}
+	if got, err := Load(); err != nil || got.Sort.Order != "name" {
+		t.Errorf("Load() = %q, %v; want the saved sort order", got.Sort.Order, err)
+	}
+	for _, dir := range []string{Dir(), filepath.Dir(target)} {
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != 1 {
+			t.Errorf("%s holds %d files, want no temporary files left", dir, len(entries))
+		}
+	}
+	if fi, _ := os.Stat(target); fi.Mode().Perm() != 0o644 {
+		t.Errorf("mode = %v, want 0644", fi.Mode().Perm())
+	}
+}
+
+func TestLoadReportsInvalidValues(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	os.MkdirAll(Dir(), 0o755)
+	os.WriteFile(Path(), []byte("[keys]\nmode = \"emoji\"\n[sort]\norder = \"random\"\n[udp]\nhost = \"box\"\n"), 0o644)
+
+	c, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "keys.mode") || !strings.Contains(err.Error(), "sort.order") {
+		t.Errorf("Load() error = %v, want keys.mode and sort.order named", err)
+	}
+	if c.Keys.Mode != "numbers" || c.Sort.Order != "default" || c.UDP.Host != "box" {
+		t.Errorf("Load() = %+v, want the bad keys at their defaults and the rest kept", c)
+	}
+
+	// Setting one key works while another is still bad, so each can be fixed.
+	if err := Set("sort.order", "name"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(); err == nil || strings.Contains(err.Error(), "sort.order") || !strings.Contains(err.Error(), "keys.mode") {
+		t.Errorf("Load() after fixing sort.order = %v, want only keys.mode reported", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	content := `# comment
+[backend]
+name = "zellij" # trailing comment
+
+[guard]
+apps = [
+  "claude",
+  'aider#1',
+]
+
+[future]
+unknown = "ignored"
+
+[udp]
+enabled = false
+`
+	c := Default()
+	if err := decode(content, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Backend.Name != "zellij" {
+		t.Errorf("backend.name = %q", c.Backend.Name)
+	}
+	if !reflect.DeepEqual(c.Guard.Apps, []string{"claude", "aider#1"}) {
+		t.Errorf("guard.apps = %v", c.Guard.Apps)
+	}
+	if c.UDP.Enabled {
+		t.Error("udp.enabled should be false")
+	}
+	if c.Keys.Mode != "numbers" {
+		t.Errorf("unset keys.mode should keep default, got %q", c.Keys.Mode)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, content := range []string{
+		"[udp\nenabled = true",
+		"[udp]\nenabled",
+		"[udp]\nenabled = maybe",
+		"[backend]\nname = tmux",
+	} {
+		c := Default()
+		if err := decode(content, &c); err == nil {
+			t.Errorf("decode(%q) should fail", content)
+		}
+	}
+}
+
+func TestGetSet(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if err := Set("udp.host", "box"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set("guard.apps", "claude, aider"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set("autostart.enabled", "false"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"udp.host":          "box",
+		"guard.apps":        `["claude", "aider"]`,
+		"autostart.enabled": "false",
+		"keys.mode":         "numbers",
+	}
+	for key, want := range tests {
+		got, err := Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("Get(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestSetRejectsBadInput(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if err := Set("nope.key", "x"); err == nil || !strings.Contains(err.Error(), "unknown config key") {
+		t.Errorf("Set(unknown) error = %v", err)
+	}
+	if err := Set("udp.enabled", "sometimes"); err == nil {
+		t.Error("Set(udp.enabled, sometimes) should fail")
+	}
+	if err := Set("keys.mode", "emoji"); err == nil {
+		t.Error("Set(keys.mode, emoji) should fail validation")
+	}
+	if err := Set("sort.order", "random"); err == nil {
+		t.Error("Set(sort.order, random) should fail validation")
+	}
+	if err := Set("theme.name", "solarized"); err == nil {
+		t.Error("Set(theme.name, solarized) should fail validation")
+	}
+	if err := Set("naming.new", "{repo}/{brnch}"); err == nil || !strings.Contains(err.Error(), "{brnch}") {
+		t.Errorf("Set(naming.new) with a typo error = %v", err)
+	}
+	if err := Set("naming.date", "{repo}-{date:2006-01-02}"); err != nil {
+		t.Errorf("Set(naming.date) = %v", err)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	keys := Keys()
+	if keys[0] != "backend.name" {
+		t.Errorf("first key = %q, want backend.name", keys[0])
+	}
+	for _, want := range []string{"udp.host", "guard.apps", "naming.date_format", "autostart.enabled"} {
+		found := false
+		for _, k := range keys {
+			if k == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Keys() missing %q", want)
+		}
+	}
+}
+
+func TestDecodeTopLevelKeysAndMapSections(t *testing.T) {
+	var v struct {
+		Name string            `toml:"name"`
+		Env  map[string]string `toml:"env"`
+	}
+	content := "name = \"api\"\n\n[env]\nPORT = \"8080\"\nHOST = \"localhost\"\n"
+	if err := Decode(content, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "api" {
+		t.Errorf("Name = %q", v.Name)
+	}
+	if want := map[string]string{"PORT": "8080", "HOST": "localhost"}; !reflect.DeepEqual(v.Env, want) {
+		t.Errorf("Env = %v, want %v", v.Env, want)
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// migrateLegacy fills c from the per-setting files used before config.toml:
+// backend, keys, udp.conf, and guard.conf. It reports whether any were found.
+// The old files are left in place so downgrading keeps working.
+func migrateLegacy(c *Config) bool {
+	dir := Dir()
+	found := false
+
+	if data, err := os.ReadFile(filepath.Join(dir, "backend")); err == nil {
+		c.Backend.Name = strings.TrimSpace(string(data))
+		found = true
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "keys")); err == nil {
+		if strings.TrimSpace(string(data)) == "letters" {
+			c.Keys.Mode = "letters"
+		}
+		found = true
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "udp.conf")); err == nil {
+		c.UDP = parseLegacyUDP(string(data))
+		found = true
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "guard.conf")); err == nil {
+		c.Guard.Apps = parseLegacyGuard(string(data))
+		found = true
+	}
+	return found
+}
+
+// parseLegacyUDP parses udp.conf's key=value lines. Defaults: enabled, no host.
+func parseLegacyUDP(content string) UDPConfig {
+	udp := UDPConfig{Enabled: true}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if k, v, ok := strings.Cut(line, "="); ok {
+			switch k {
+			case "enabled":
+				udp.Enabled = v == "true"
+			case "host":
+				udp.Host = v
+			}
+		}
+	}
+	return udp
+}
+
+// parseLegacyGuard extracts app names from guard.conf, skipping comments and
+// blanks and dropping duplicates.
+func parseLegacyGuard(content string) []string {
+	apps := []string{}
+	seen := map[string]bool{}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !seen[line] {
+			apps = append(apps, line)
+			seen[line] = true
+		}
+	}
+	return apps
+}
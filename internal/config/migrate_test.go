@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeLegacy(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadMigratesLegacyFiles(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := Dir()
+	writeLegacy(t, dir, "backend", "tmux\n")
+	writeLegacy(t, dir, "keys", "letters\n")
+	writeLegacy(t, dir, "udp.conf", "enabled=false\nhost=box\n")
+	writeLegacy(t, dir, "guard.conf", "# Apps guarded by zpick\nclaude\naider\n")
+
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Backend.Name != "tmux" || c.Keys.Mode != "letters" {
+		t.Errorf("backend/keys not migrated: %+v", c)
+	}
+	if c.UDP != (UDPConfig{Enabled: false, Host: "box"}) {
+		t.Errorf("udp not migrated: %+v", c.UDP)
+	}
+	if !reflect.DeepEqual(c.Guard.Apps, []string{"claude", "aider"}) {
+		t.Errorf("guard not migrated: %v", c.Guard.Apps)
+	}
+	if _, err := os.Stat(Path()); err != nil {
+		t.Error("migration should write config.toml")
+	}
+
+	// Later edits to legacy files are ignored once config.toml exists.
+	writeLegacy(t, dir, "backend", "zellij\n")
+	c, _ = Load()
+	if c.Backend.Name != "tmux" {
+		t.Errorf("backend = %q after migration, want tmux", c.Backend.Name)
+	}
+}
+
+func TestParseLegacyGuard(t *testing.T) {
+	content := `# Apps guarded by zpick
+claude
+codex
+
+# Another comment
+opencode
+claude
+`
+	apps := parseLegacyGuard(content)
+	if !reflect.DeepEqual(apps, []string{"claude", "codex", "opencode"}) {
+		t.Errorf("unexpected apps (want deduped): %v", apps)
+	}
+	if apps := parseLegacyGuard(""); len(apps) != 0 {
+		t.Errorf("expected 0 apps, got %v", apps)
+	}
+}
+
+func TestParseLegacyUDP(t *testing.T) {
+	if got := parseLegacyUDP(""); got != (UDPConfig{Enabled: true}) {
+		t.Errorf("empty udp.conf = %+v, want enabled default", got)
+	}
+	if got := parseLegacyUDP("enabled=true\nhost=h\n"); got != (UDPConfig{Enabled: true, Host: "h"}) {
+		t.Errorf("parseLegacyUDP = %+v", got)
+	}
+}
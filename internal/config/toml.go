@@ -0,0 +1,234 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// zpick's config only needs a small subset of TOML: [section] headers and
+// key = value pairs whose values are strings, booleans, integers, or arrays
+// of strings. Unknown sections and keys are ignored so a config written by a
+// newer zp still loads.
+
+// encode renders c as TOML, one [section] per Config field.
+func encode(c Config) string {
+	var b strings.Builder
+	b.WriteString("# zpick configuration — see `zp config` for details.\n")
+
+	root := reflect.ValueOf(c)
+	rt := root.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		fmt.Fprintf(&b, "\n[%s]\n", rt.Field(i).Tag.Get("toml"))
+		sv := root.Field(i)
+		st := sv.Type()
+		for j := 0; j < st.NumField(); j++ {
+			fmt.Fprintf(&b, "%s = %s\n", st.Field(j).Tag.Get("toml"), formatValue(sv.Field(j)))
+		}
+	}
+	return b.String()
+}
+
+// formatValue renders a single field value as a TOML literal.
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Slice:
+		var items []string
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, strconv.Quote(v.Index(i).String()))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return `""`
+}
+
+// decode parses TOML content onto c, leaving unset keys untouched.
+func decode(content string, c *Config) error {
+	return Decode(content, c)
+}
+
+// Decode parses TOML content onto the struct pointed to by v, leaving unset
+// keys untouched. Top-level keys map to fields by their toml tag; a [section]
+// maps to a struct field (keys are its fields) or a map[string]string field
+// (keys are free-form).
+func Decode(content string, v any) error {
+	root := reflect.ValueOf(v).Elem()
+	section := ""
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(stripComment(lines[i]))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return fmt.Errorf("line %d: malformed section header", lineNo)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		key = strings.TrimSpace(key)
+		raw = strings.TrimSpace(raw)
+		// Arrays may span several lines.
+		for strings.HasPrefix(raw, "[") && !strings.HasSuffix(raw, "]") && i+1 < len(lines) {
+			i++
+			raw += " " + strings.TrimSpace(stripComment(lines[i]))
+		}
+
+		if m, ok := mapSection(root, section); ok {
+			value, err := parseString(raw)
+			if err != nil {
+				return fmt.Errorf("line %d: %s.%s: %w", lineNo, section, key, err)
+			}
+			if m.IsNil() {
+				m.Set(reflect.MakeMap(m.Type()))
+			}
+			m.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(value))
+			continue
+		}
+		field, ok := lookup(root, joinKey(section, key))
+		if !ok {
+			continue
+		}
+		if err := setFromLiteral(field, raw); err != nil {
+			return fmt.Errorf("line %d: %s: %w", lineNo, joinKey(section, key), err)
+		}
+	}
+	return nil
+}
+
+// mapSection returns the map[string]string field for a [section], if any.
+func mapSection(root reflect.Value, section string) (reflect.Value, bool) {
+	if section == "" {
+		return reflect.Value{}, false
+	}
+	f, ok := fieldByTag(root, section)
+	if !ok || f.Kind() != reflect.Map {
+		return reflect.Value{}, false
+	}
+	return f, true
+}
+
+func joinKey(section, key string) string {
+	if section == "" {
+		return key
+	}
+	return section + "." + key
+}
+
+func setFromLiteral(v reflect.Value, raw string) error {
+	switch v.Kind() {
+	case reflect.String:
+		s, err := parseString(raw)
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("expected true or false, got %s", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("expected an integer, got %s", raw)
+		}
+		v.SetInt(int64(n))
+	case reflect.Slice:
+		list, err := parseArray(raw)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(list))
+	}
+	return nil
+}
+
+// parseString parses a basic ("...") or literal ('...') TOML string.
+func parseString(raw string) (string, error) {
+	if len(raw) >= 2 && raw[0] == '\'' && raw[len(raw)-1] == '\'' {
+		return raw[1 : len(raw)-1], nil
+	}
+	if len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"' {
+		s, err := strconv.Unquote(raw)
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", raw)
+		}
+		return s, nil
+	}
+	return "", fmt.Errorf("expected a quoted string, got %s", raw)
+}
+
+// parseArray parses a TOML array of strings.
+func parseArray(raw string) ([]string, error) {
+	if !strings.HasPrefix(raw, "[") || !strings.HasSuffix(raw, "]") {
+		return nil, fmt.Errorf("expected an array, got %s", raw)
+	}
+	list := []string{}
+	for _, item := range splitArray(raw[1 : len(raw)-1]) {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		s, err := parseString(item)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+// splitArray splits array contents on commas outside of quotes.
+func splitArray(s string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0 && c == '\\' && quote == '"':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// stripComment removes a trailing # comment that isn't inside a string.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0 && c == '\\' && quote == '"':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,78 @@
+// Package git inspects the git repository a session's directory belongs to.
+// Apart from Dirty, it reads .git directly rather than running git, so the
+// picker stays fast and works where git isn't installed.
+package git
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Root walks up from dir to the directory holding .git (a directory, or a
+// file in worktrees and submodules). It stops before $HOME and /, returning
+// "" when dir isn't in a repository.
+func Root(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	home, _ := os.UserHomeDir()
+	for d := filepath.Clean(dir); d != home && d != filepath.Dir(d); d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d
+		}
+	}
+	return ""
+}
+
+// gitDir returns the git directory of the repository at root, following the
+// "gitdir:" pointer that worktrees and submodules have in place of a .git
+// directory.
+func gitDir(root string) string {
+	dotGit := filepath.Join(root, ".git")
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return dotGit // a directory, or missing
+	}
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return dotGit
+	}
+	dir = strings.TrimSpace(dir)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	return dir
+}
+
+// Branch returns the branch checked out in the repository at root, the
+// short commit hash when HEAD is detached, or "" when it can't be read.
+func Branch(root string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir(root), "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(data))
+	if ref, ok := strings.CutPrefix(head, "ref: "); ok {
+		return strings.TrimPrefix(ref, "refs/heads/")
+	}
+	if len(head) > 7 {
+		return head[:7]
+	}
+	return head
+}
+
+// Dirty reports whether the work tree at root has uncommitted changes to
+// tracked files. It runs git status, giving up after timeout; any failure
+// counts as clean. --no-optional-locks keeps it from refreshing the index,
+// which would take index.lock under a git command running in the session.
+func Dirty(root string, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "git", "--no-optional-locks", "-C", root, "status", "--porcelain", "--untracked-files=no").Output()
+	return err == nil && len(bytes.TrimSpace(out)) > 0
+}
@@ -0,0 +1,79 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRootAndBranch(t *testing.T) {
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "ref: refs/heads/feature/login\n")
+	os.MkdirAll(filepath.Join(repo, "src", "cmd"), 0o755)
+
+	if got := Root(filepath.Join(repo, "src", "cmd")); got != repo {
+		t.Errorf("Root() = %q, want %q", got, repo)
+	}
+	if got := Branch(repo); got != "feature/login" {
+		t.Errorf("Branch() = %q", got)
+	}
+	if got := Root(t.TempDir()); got != "" {
+		t.Errorf("Root() outside a repo = %q", got)
+	}
+}
+
+func TestBranchDetachedAndWorktree(t *testing.T) {
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "0123456789abcdef0123456789abcdef01234567\n")
+	if got := Branch(repo); got != "0123456" {
+		t.Errorf("detached Branch() = %q", got)
+	}
+
+	// A worktree's .git is a file pointing at its git dir.
+	wt := t.TempDir()
+	gitdir := filepath.Join(repo, ".git", "worktrees", "wt")
+	writeFile(t, filepath.Join(gitdir, "HEAD"), "ref: refs/heads/fix\n")
+	writeFile(t, filepath.Join(wt, ".git"), "gitdir: "+gitdir+"\n")
+	if got := Branch(wt); got != "fix" {
+		t.Errorf("worktree Branch() = %q", got)
+	}
+}
+
+func TestDirty(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	if Dirty(repo, 5*time.Second) {
+		t.Error("a fresh repository should be clean")
+	}
+
+	writeFile(t, filepath.Join(repo, "main.go"), "package main\n")
+	if Dirty(repo, 5*time.Second) {
+		t.Error("untracked files shouldn't count as changes")
+	}
+	if out, err := exec.Command("git", "-C", repo, "add", "main.go").CombinedOutput(); err != nil {
+		t.Fatalf("git add: %v\n%s", err, out)
+	}
+	if !Dirty(repo, 5*time.Second) {
+		t.Error("a staged file should make the repository dirty")
+	}
+	if Dirty(t.TempDir(), 5*time.Second) {
+		t.Error("a directory outside any repository should count as clean")
+	}
+}
@@ -0,0 +1,101 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Worktree is one working tree of a repository.
+type Worktree struct {
+	Path   string
+	Branch string // checked-out branch, or "" when HEAD is detached
+}
+
+// Name returns what the worktree is known by: its branch, or its
+// directory's name when HEAD is detached.
+func (w Worktree) Name() string {
+	if w.Branch != "" {
+		return w.Branch
+	}
+	return filepath.Base(w.Path)
+}
+
+// run runs git in the repository at root and returns its trimmed output,
+// with git's own message as the error.
+func run(root string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Worktrees lists the worktrees of the repository at root (any of its
+// worktrees will do), the main one first.
+func Worktrees(root string) ([]Worktree, error) {
+	out, err := run(root, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	var trees []Worktree
+	sc := bufio.NewScanner(strings.NewReader(out))
+	for sc.Scan() {
+		line := sc.Text()
+		if path, ok := strings.CutPrefix(line, "worktree "); ok {
+			trees = append(trees, Worktree{Path: path})
+		} else if ref, ok := strings.CutPrefix(line, "branch "); ok && len(trees) > 0 {
+			trees[len(trees)-1].Branch = strings.TrimPrefix(ref, "refs/heads/")
+		}
+	}
+	return trees, nil
+}
+
+// WorktreeDir returns where a new worktree for branch goes: beside the main
+// worktree at main, named after it and the branch ("api-feature-login").
+func WorktreeDir(main, branch string) string {
+	return filepath.Join(filepath.Dir(main), filepath.Base(main)+"-"+strings.ReplaceAll(branch, "/", "-"))
+}
+
+// AddWorktree checks out branch in a new worktree at path. A branch that
+// exists only on a remote is tracked from there, and a branch that doesn't
+// exist at all is created from HEAD. branch must be a valid branch name, so
+// "-f" or "--detach" are refused rather than read as options.
+func AddWorktree(root, path, branch string) error {
+	if err := CheckBranch(root, branch); err != nil {
+		return err
+	}
+	args := []string{"worktree", "add", "--", path, branch}
+	if !hasBranch(root, branch) {
+		args = []string{"worktree", "add", "-b", branch, "--", path}
+	}
+	_, err := run(root, args...)
+	return err
+}
+
+// CheckBranch reports an error unless branch is a valid name for a new
+// branch, as git check-ref-format --branch sees it. Shorthands like @{-1},
+// which it would expand to another branch's name, are refused too.
+func CheckBranch(root, branch string) error {
+	out, err := run(root, "check-ref-format", "--branch", branch)
+	if err != nil || out != branch {
+		return fmt.Errorf("%q is not a valid branch name", branch)
+	}
+	return nil
+}
+
+// hasBranch reports whether branch exists locally or on a remote (where
+// git worktree add picks it up).
+func hasBranch(root, branch string) bool {
+	out, err := run(root, "for-each-ref", "--format=%(refname)", "refs/heads/"+branch, "refs/remotes/*/"+branch)
+	return err == nil && out != ""
+}
@@ -0,0 +1,85 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// newRepo creates a repository with one commit on main.
+func newRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := filepath.Join(t.TempDir(), "api")
+	writeFile(t, filepath.Join(repo, "README"), "api\n")
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"add", "README"},
+		{"-c", "user.name=zp", "-c", "user.email=zp@example.com", "commit", "-q", "-m", "init"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return repo
+}
+
+func TestWorktrees(t *testing.T) {
+	repo := newRepo(t)
+
+	dir := WorktreeDir(repo, "feature/login")
+	if want := filepath.Join(filepath.Dir(repo), "api-feature-login"); dir != want {
+		t.Errorf("WorktreeDir() = %q, want %q", dir, want)
+	}
+	if err := AddWorktree(repo, dir, "feature/login"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "README")); err != nil {
+		t.Errorf("worktree not checked out: %v", err)
+	}
+
+	// Any worktree lists them all, the main one first.
+	trees, err := Worktrees(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trees) != 2 || trees[0].Branch != "main" || trees[1].Branch != "feature/login" {
+		t.Fatalf("Worktrees() = %+v", trees)
+	}
+	if got := trees[1].Name(); got != "feature/login" {
+		t.Errorf("Name() = %q", got)
+	}
+
+	// git refuses a second worktree on the same branch.
+	other := WorktreeDir(repo, "login-copy")
+	if err := AddWorktree(repo, other, "feature/login"); err == nil {
+		t.Error("checking out a branch already in a worktree should fail")
+	}
+	if got := (Worktree{Path: "/src/api-wt"}).Name(); got != "api-wt" {
+		t.Errorf("detached Name() = %q", got)
+	}
+}
+
+func TestAddWorktreeRefusesOptionLikeBranches(t *testing.T) {
+	repo := newRepo(t)
+	for _, branch := range []string{"-f", "--detach", "a..b", "@{-1}"} {
+		dir := filepath.Join(t.TempDir(), "wt")
+		if err := AddWorktree(repo, dir, branch); err == nil {
+			t.Errorf("AddWorktree(%q) succeeded, want an invalid branch error", branch)
+		}
+		if _, err := os.Stat(dir); err == nil {
+			t.Errorf("AddWorktree(%q) created %s", branch, dir)
+		}
+	}
+	trees, err := Worktrees(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trees) != 1 {
+		t.Errorf("Worktrees() = %+v, want only the main one", trees)
+	}
+}
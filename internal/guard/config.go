@@ -3,64 +3,38 @@ package guard
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"regexp"
 	"strings"
 
-	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/config"
 )
 
 // validName matches valid shell function names (letters, digits, underscores, hyphens).
 var validName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*$`)
 
 // DefaultApps are the apps guarded by default.
-var DefaultApps = []string{"claude", "codex", "opencode"}
+var DefaultApps = config.DefaultGuardApps
 
-// ConfigPath returns the path to the guard config file.
+// ConfigPath returns the path to the config file holding the guard list.
 func ConfigPath() string {
-	return filepath.Join(backend.ConfigDir(), "guard.conf")
+	return config.Path()
 }
 
-// ReadConfig reads the guard config file and returns the list of guarded app names.
-// Returns DefaultApps if the file does not exist.
+// ReadConfig returns the list of guarded app names from the [guard] section.
+// Returns DefaultApps if none are configured.
 func ReadConfig() ([]string, error) {
-	data, err := os.ReadFile(ConfigPath())
+	c, err := config.Load()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return DefaultApps, nil
-		}
 		return nil, fmt.Errorf("cannot read guard config: %w", err)
 	}
-	return parseConfig(string(data)), nil
-}
-
-// parseConfig extracts app names from config content, skipping comments and blanks.
-func parseConfig(content string) []string {
-	var apps []string
-	seen := map[string]bool{}
-	for _, line := range strings.Split(content, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		if !seen[line] {
-			apps = append(apps, line)
-			seen[line] = true
-		}
-	}
-	return apps
+	return c.Guard.Apps, nil
 }
 
-// WriteConfig writes the app list to the config file, creating directories as needed.
+// WriteConfig saves the app list to the config file, creating directories as needed.
 func WriteConfig(apps []string) error {
-	path := ConfigPath()
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return fmt.Errorf("cannot create config dir: %w", err)
-	}
-
 	// Deduplicate
 	seen := map[string]bool{}
-	var deduped []string
+	deduped := []string{}
 	for _, app := range apps {
 		if !seen[app] {
 			deduped = append(deduped, app)
@@ -68,13 +42,10 @@ func WriteConfig(apps []string) error {
 		}
 	}
 
-	var buf strings.Builder
-	buf.WriteString("# Apps guarded by zpick (one per line)\n")
-	for _, app := range deduped {
-		buf.WriteString(app)
-		buf.WriteByte('\n')
-	}
-	return os.WriteFile(path, []byte(buf.String()), 0644)
+	return config.Update(func(c *config.Config) error {
+		c.Guard.Apps = deduped
+		return nil
+	})
 }
 
 // ValidateName checks if a name is valid for use as a guarded app.
@@ -132,9 +103,12 @@ func FuncName(app string) string {
 
 // EnsureConfig creates the config file with defaults if it doesn't exist.
 func EnsureConfig() error {
-	path := ConfigPath()
-	if _, err := os.Stat(path); err == nil {
+	if _, err := os.Stat(ConfigPath()); err == nil {
 		return nil // already exists
 	}
-	return WriteConfig(DefaultApps)
+	c, err := config.Load()
+	if err != nil {
+		return err
+	}
+	return config.Save(c)
 }
@@ -22,36 +22,10 @@ func TestValidateName(t *testing.T) {
 	}
 }
 
-func TestParseConfig(t *testing.T) {
-	content := `# Apps guarded by zpick
-claude
-codex
-
-# Another comment
-opencode
-claude
-`
-	apps := parseConfig(content)
-
-	if len(apps) != 3 {
-		t.Fatalf("expected 3 apps (deduped), got %d: %v", len(apps), apps)
-	}
-	if apps[0] != "claude" || apps[1] != "codex" || apps[2] != "opencode" {
-		t.Errorf("unexpected apps: %v", apps)
-	}
-}
-
-func TestParseConfigEmpty(t *testing.T) {
-	apps := parseConfig("")
-	if len(apps) != 0 {
-		t.Errorf("expected 0 apps, got %d", len(apps))
-	}
-}
-
 func TestWriteAndReadConfig(t *testing.T) {
 	dir := t.TempDir()
 	configDir := filepath.Join(dir, "zpick")
-	configPath := filepath.Join(configDir, "guard.conf")
+	configPath := filepath.Join(configDir, "config.toml")
 
 	// Override config path via XDG_CONFIG_HOME
 	origXDG := os.Getenv("XDG_CONFIG_HOME")
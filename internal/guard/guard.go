@@ -1,27 +1,19 @@
 package guard
 
 import (
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"time"
 
+	"github.com/nerveband/zpick/internal/autorun"
 	"github.com/nerveband/zpick/internal/backend"
 	"github.com/nerveband/zpick/internal/picker"
+	"github.com/nerveband/zpick/internal/theme"
 	"golang.org/x/term"
 )
 
-const (
-	reset   = "\033[0m"
-	dim     = "\033[2m"
-	boldYel = "\033[1;33m"
-	boldWht = "\033[1;97m"
-	boldGrn = "\033[1;32m"
-
-	timeout = 10 * time.Second
-)
+const timeout = 10 * time.Second
 
 // Run shows the guard prompt and returns a shell command to eval, or empty string.
 func Run(b backend.Backend, argv []string) (string, error) {
@@ -36,9 +28,10 @@ func Run(b backend.Backend, argv []string) (string, error) {
 	}
 	defer tty.Close()
 
+	t := theme.Load()
 	fmt.Fprintf(tty, "\n  %s⚡%s Not in a %s session. Press %sENTER%s to pick one (%ds)  %sesc%s %sskip%s\n",
-		boldYel, reset, b.Name(), boldWht, reset, int(timeout.Seconds()), dim, reset, dim, reset)
-	fmt.Fprintf(tty, "  %s>%s ", boldYel, reset)
+		t.BoldYellow, t.Reset, b.Name(), t.BoldWhite, t.Reset, int(timeout.Seconds()), t.Dim, t.Reset, t.Dim, t.Reset)
+	fmt.Fprintf(tty, "  %s>%s ", t.BoldYellow, t.Reset)
 
 	action := waitForKey(tty, timeout)
 
@@ -123,51 +116,25 @@ func runPicker(tty *os.File, b backend.Backend, argv []string) (string, error) {
 	}
 
 	if len(argv) > 0 {
-		encoded := encodeArgv(argv)
+		encoded := autorun.Encode(argv)
 		if encoded != "" {
-			fmt.Fprintf(tty, "  %srun:%s %s\n", dim, reset, formatArgv(argv))
-			return fmt.Sprintf("ZPICK_AUTORUN=%s %s", encoded, cmd), nil
+			t := theme.Load()
+			fmt.Fprintf(tty, "  %srun:%s %s\n", t.Dim, t.Reset, formatArgv(argv))
+			return fmt.Sprintf("%s=%s %s", autorun.EnvVar, encoded, cmd), nil
 		}
 	}
 
 	return cmd, nil
 }
 
-func encodeArgv(argv []string) string {
-	if len(argv) == 0 {
-		return ""
-	}
-	data, err := json.Marshal(argv)
-	if err != nil {
-		return ""
-	}
-	return base64.StdEncoding.EncodeToString(data)
-}
-
-// DecodeArgv decodes a ZPICK_AUTORUN value back into argv.
-func DecodeArgv(encoded string) ([]string, error) {
-	data, err := base64.StdEncoding.DecodeString(encoded)
-	if err != nil {
-		return nil, fmt.Errorf("invalid base64: %w", err)
-	}
-	var argv []string
-	if err := json.Unmarshal(data, &argv); err != nil {
-		return nil, fmt.Errorf("invalid JSON: %w", err)
-	}
-	if len(argv) == 0 {
-		return nil, fmt.Errorf("empty argv")
-	}
-	return argv, nil
-}
-
 // Autorun reads ZPICK_AUTORUN, decodes argv, and execs the command.
 func Autorun() error {
-	encoded := os.Getenv("ZPICK_AUTORUN")
+	encoded := os.Getenv(autorun.EnvVar)
 	if encoded == "" {
 		return nil
 	}
 
-	argv, err := DecodeArgv(encoded)
+	argv, err := autorun.Decode(encoded)
 	if err != nil {
 		return nil
 	}
@@ -177,7 +144,7 @@ func Autorun() error {
 		return fmt.Errorf("%s: command not found", argv[0])
 	}
 
-	os.Unsetenv("ZPICK_AUTORUN")
+	os.Unsetenv(autorun.EnvVar)
 
 	return backend.ExecCommand(path, argv)
 }
@@ -0,0 +1,103 @@
+package picker
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/config"
+	"github.com/nerveband/zpick/internal/git"
+)
+
+// gitTimeout bounds each git status run; a repository too slow to answer
+// shows its branch without the dirty marker.
+const gitTimeout = 300 * time.Millisecond
+
+// branchInfo is the state of the repository a session was started in.
+type branchInfo struct {
+	name  string
+	dirty bool // uncommitted changes to tracked files
+}
+
+// label renders the branch for a session row: the name, then ± if dirty.
+func (b branchInfo) label() string {
+	if b.name == "" {
+		return ""
+	}
+	label := fmt.Sprintf(" %s%s%s", magenta, b.name, reset)
+	if b.dirty {
+		label += fmt.Sprintf("%s±%s", yellow, reset)
+	}
+	return label
+}
+
+// readGitStatus returns whether rows show their branch (git.status).
+func readGitStatus() bool {
+	c, err := config.Load()
+	return err != nil || c.Git.Status
+}
+
+// sessionBranches inspects the repository of every local session's start
+// directory, keyed by StartedIn. Each repository is checked once, and the
+// git status runs happen in parallel.
+func sessionBranches(sessions []backend.Session) map[string]branchInfo {
+	roots := map[string]string{} // StartedIn -> repository root
+	repos := map[string]*branchInfo{}
+	for _, s := range sessions {
+		if s.Host != "" || s.StartedIn == "" {
+			continue
+		}
+		if _, ok := roots[s.StartedIn]; ok {
+			continue
+		}
+		root := git.Root(s.StartedIn)
+		roots[s.StartedIn] = root
+		if root != "" && repos[root] == nil {
+			repos[root] = &branchInfo{name: git.Branch(root)}
+		}
+	}
+
+	var wg sync.WaitGroup
+	for root, info := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info.dirty = git.Dirty(root, gitTimeout)
+		}()
+	}
+	wg.Wait()
+
+	branches := map[string]branchInfo{}
+	for dir, root := range roots {
+		if info := repos[root]; info != nil {
+			branches[dir] = *info
+		}
+	}
+	return branches
+}
+
+// branchCache keeps the sessionBranches of the last listing, so redraws and
+// keypresses that leave the sessions as they were don't run git again.
+type branchCache struct {
+	listing  string
+	branches map[string]branchInfo
+}
+
+// get returns the branches for sessions, inspecting the repositories again
+// only when the local sessions or their start directories have changed.
+func (c *branchCache) get(sessions []backend.Session) map[string]branchInfo {
+	var local []string
+	for _, s := range sessions {
+		if s.Host == "" && s.StartedIn != "" {
+			local = append(local, s.Name+"\x00"+s.StartedIn)
+		}
+	}
+	slices.Sort(local)
+	if listing := strings.Join(local, "\n"); c.branches == nil || listing != c.listing {
+		c.listing, c.branches = listing, sessionBranches(sessions)
+	}
+	return c.branches
+}
@@ -0,0 +1,68 @@
+package picker
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+func TestSessionBranches(t *testing.T) {
+	repo := t.TempDir()
+	os.MkdirAll(filepath.Join(repo, ".git"), 0o755)
+	os.WriteFile(filepath.Join(repo, ".git", "HEAD"), []byte("ref: refs/heads/login\n"), 0o644)
+	src := filepath.Join(repo, "src")
+	os.Mkdir(src, 0o755)
+	other := t.TempDir()
+
+	sessions := []backend.Session{
+		{Name: "api", StartedIn: repo},
+		{Name: "api-src", StartedIn: src},
+		{Name: "scratch", StartedIn: other},
+		{Name: "remote", StartedIn: repo, Host: "devbox"},
+	}
+	branches := sessionBranches(sessions)
+	if branches[repo].name != "login" || branches[src].name != "login" {
+		t.Errorf("branches = %+v, want login for both sessions in the repo", branches)
+	}
+	if _, ok := branches[other]; ok || len(branches) != 2 {
+		t.Errorf("branches = %+v, want only the repository's directories", branches)
+	}
+
+	rows := sessionRows(sessions[:3], pageRows{branch: branches}, "", -1)
+	if !strings.Contains(rows[0], "login") || strings.Contains(rows[2], "login") {
+		t.Errorf("rows = %q", rows)
+	}
+	dirty := branchInfo{name: "login", dirty: true}.label()
+	if !strings.Contains(dirty, "±") || (branchInfo{}).label() != "" {
+		t.Errorf("labels: dirty %q", dirty)
+	}
+}
+
+func TestBranchCache(t *testing.T) {
+	repo := t.TempDir()
+	head := filepath.Join(repo, ".git", "HEAD")
+	os.MkdirAll(filepath.Dir(head), 0o755)
+	os.WriteFile(head, []byte("ref: refs/heads/login\n"), 0o644)
+
+	var c branchCache
+	sessions := []backend.Session{{Name: "api", StartedIn: repo}}
+	if got := c.get(sessions)[repo].name; got != "login" {
+		t.Fatalf("branch = %q, want login", got)
+	}
+
+	// Redrawing the same listing reuses what git said last time.
+	os.WriteFile(head, []byte("ref: refs/heads/main\n"), 0o644)
+	if got := c.get(slices.Clone(sessions))[repo].name; got != "login" {
+		t.Errorf("branch for the same listing = %q, want the cached login", got)
+	}
+
+	// A new listing inspects the repository again.
+	sessions = append(sessions, backend.Session{Name: "api-2", StartedIn: repo})
+	if got := c.get(sessions)[repo].name; got != "main" {
+		t.Errorf("branch after the listing changed = %q, want main", got)
+	}
+}
@@ -0,0 +1,101 @@
+package picker
+
+// keyKind classifies a decoded keypress.
+type keyKind int
+
+const (
+	keyChar keyKind = iota // a single byte, in key.ch
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyUnknown // an escape sequence we don't handle
+)
+
+// key is one decoded keypress.
+type key struct {
+	kind keyKind
+	ch   byte
+}
+
+// escapeSequences maps the CSI and SS3 sequences terminals send for the
+// navigation keys (with and without application cursor mode).
+var escapeSequences = map[string]keyKind{
+	"\x1b[A":  keyUp,
+	"\x1bOA":  keyUp,
+	"\x1b[B":  keyDown,
+	"\x1bOB":  keyDown,
+	"\x1b[D":  keyLeft,
+	"\x1bOD":  keyLeft,
+	"\x1b[C":  keyRight,
+	"\x1bOC":  keyRight,
+	"\x1b[5~": keyPageUp,
+	"\x1b[6~": keyPageDown,
+	"\x1b[H":  keyHome,
+	"\x1bOH":  keyHome,
+	"\x1b[1~": keyHome,
+	"\x1b[7~": keyHome,
+	"\x1b[F":  keyEnd,
+	"\x1bOF":  keyEnd,
+	"\x1b[4~": keyEnd,
+	"\x1b[8~": keyEnd,
+}
+
+// decodeKey turns the bytes of one read into a keypress. Besides the
+// terminal's navigation keys it understands vim-style J/K (uppercase, since
+// lowercase letters are session keys) and the emacs-style Ctrl-N/P/F/B.
+func decodeKey(input []byte) key {
+	if len(input) == 0 {
+		return key{kind: keyUnknown}
+	}
+	if input[0] == 27 && len(input) > 1 {
+		if kind, ok := escapeSequences[string(input)]; ok {
+			return key{kind: kind}
+		}
+		return key{kind: keyUnknown}
+	}
+	switch input[0] {
+	case 'K', 16: // Ctrl-P
+		return key{kind: keyUp}
+	case 'J', 14: // Ctrl-N
+		return key{kind: keyDown}
+	case 2: // Ctrl-B
+		return key{kind: keyPageUp}
+	case 6: // Ctrl-F
+		return key{kind: keyPageDown}
+	}
+	return key{kind: keyChar, ch: input[0]}
+}
+
+// moveCursor applies a navigation key to a cursor over n rows, where -1
+// means no row is highlighted yet. pageSize is how far PgUp/PgDn jump.
+func moveCursor(cursor, n, pageSize int, kind keyKind) int {
+	if n == 0 {
+		return -1
+	}
+	switch kind {
+	case keyUp:
+		if cursor < 0 {
+			return n - 1
+		}
+		cursor--
+	case keyDown:
+		cursor++
+	case keyPageUp:
+		cursor -= pageSize
+	case keyPageDown:
+		if cursor < 0 {
+			cursor = 0
+		}
+		cursor += pageSize
+	case keyHome:
+		cursor = 0
+	case keyEnd:
+		cursor = n - 1
+	}
+	return min(max(cursor, 0), n-1)
+}
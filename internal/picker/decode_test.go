@@ -0,0 +1,117 @@
+package picker
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+func TestDecodeKey(t *testing.T) {
+	tests := []struct {
+		input string
+		want  key
+	}{
+		{"\x1b[A", key{kind: keyUp}},
+		{"\x1bOB", key{kind: keyDown}},
+		{"\x1b[5~", key{kind: keyPageUp}},
+		{"\x1b[6~", key{kind: keyPageDown}},
+		{"\x1b[H", key{kind: keyHome}},
+		{"\x1b[4~", key{kind: keyEnd}},
+		{"K", key{kind: keyUp}},
+		{"J", key{kind: keyDown}},
+		{"\x0e", key{kind: keyDown}},
+		{"\x10", key{kind: keyUp}},
+		{"\x1b[Z", key{kind: keyUnknown}},
+		{"\x1b", key{kind: keyChar, ch: 27}},
+		{"k", key{kind: keyChar, ch: 'k'}},
+		{"3", key{kind: keyChar, ch: '3'}},
+	}
+	for _, tt := range tests {
+		if got := decodeKey([]byte(tt.input)); got != tt.want {
+			t.Errorf("decodeKey(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestMoveCursor(t *testing.T) {
+	tests := []struct {
+		cursor, n int
+		kind      keyKind
+		want      int
+	}{
+		{-1, 5, keyDown, 0},
+		{-1, 5, keyUp, 4},
+		{0, 5, keyUp, 0},
+		{4, 5, keyDown, 4},
+		{2, 5, keyDown, 3},
+		{1, 30, keyPageDown, 11},
+		{25, 30, keyPageDown, 29},
+		{5, 30, keyPageUp, 0},
+		{-1, 30, keyPageDown, 10},
+		{7, 30, keyHome, 0},
+		{7, 30, keyEnd, 29},
+		{3, 0, keyDown, -1},
+	}
+	for _, tt := range tests {
+		if got := moveCursor(tt.cursor, tt.n, 10, tt.kind); got != tt.want {
+			t.Errorf("moveCursor(%d, %d, %v) = %d, want %d", tt.cursor, tt.n, tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestViewSetSessionsClampsCursor(t *testing.T) {
+	v := &view{cursor: 2}
+	v.setSessions([]backend.Session{{Name: "a"}, {Name: "b"}, {Name: "c"}}, nil)
+	if s, ok := v.selected(); !ok || s.Name != "c" {
+		t.Fatalf("selected = %+v, %v; want c", s, ok)
+	}
+
+	// A session killed elsewhere shrinks the list under the cursor.
+	v.setSessions([]backend.Session{{Name: "a"}}, nil)
+	if v.cursor != 0 {
+		t.Errorf("cursor = %d after shrinking to one session, want 0", v.cursor)
+	}
+
+	v.setSessions(nil, nil)
+	if _, ok := v.selected(); ok {
+		t.Error("empty list should have no selection")
+	}
+}
+
+func TestViewPages(t *testing.T) {
+	var sessions []backend.Session
+	for i := range 40 {
+		sessions = append(sessions, backend.Session{Name: fmt.Sprintf("s%02d", i+1)})
+	}
+	v := &view{cursor: -1}
+	v.setSessions(sessions, nil)
+	if v.pages() != 2 || len(v.pageSessions()) != MaxSessions {
+		t.Fatalf("pages = %d, first page %d sessions", v.pages(), len(v.pageSessions()))
+	}
+
+	v.cursor = 20
+	v.turnPage(1)
+	page := v.pageSessions()
+	if len(page) != 8 || page[0].Name != "s33" {
+		t.Fatalf("second page = %d sessions starting %q", len(page), page[0].Name)
+	}
+	if v.cursor != 7 {
+		t.Errorf("cursor = %d on an 8-row page, want 7", v.cursor)
+	}
+	if got := pickerActionForInput([]byte{'1'}, page, nil); got.Name != "s33" {
+		t.Errorf("'1' on page 2 = %+v, want s33", got)
+	}
+
+	v.turnPage(1)
+	if v.page != 0 {
+		t.Errorf("next from the last page = page %d, want wrap to 0", v.page)
+	}
+
+	// Sessions killed elsewhere can take the shown page away.
+	v.page = 1
+	v.setSessions(sessions[:10], nil)
+	if v.page != 0 || len(v.pageSessions()) != 10 {
+		t.Errorf("after shrinking: page %d with %d sessions", v.page, len(v.pageSessions()))
+	}
+}
@@ -0,0 +1,45 @@
+package picker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+func TestSessionDetail(t *testing.T) {
+	now := time.Unix(1771700000, 0)
+	tests := []struct {
+		name string
+		s    backend.Session
+		want string
+	}{
+		{name: "no metadata", s: backend.Session{Name: "a"}, want: ""},
+		{name: "command and activity", s: backend.Session{Command: "nvim", LastActivity: now.Add(-3 * time.Hour)}, want: "nvim · 3h"},
+		{name: "windows", s: backend.Session{Windows: 4, CreatedAt: now.Add(-10 * time.Minute)}, want: "4w · 10m"},
+		{name: "single window hidden", s: backend.Session{Windows: 1}, want: ""},
+		{name: "exited", s: backend.Session{EndedAt: now.Add(-time.Minute), ExitCode: 1, LastActivity: now}, want: "exited 1"},
+	}
+	for _, tt := range tests {
+		if got := sessionDetail(tt.s, now); got != tt.want {
+			t.Errorf("%s: sessionDetail() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{30 * time.Second, "now"},
+		{5 * time.Minute, "5m"},
+		{2 * time.Hour, "2h"},
+		{50 * time.Hour, "2d"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.d); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
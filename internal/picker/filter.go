@@ -0,0 +1,231 @@
+package picker
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+// filterKeys label the matches in filter mode, and pressing one picks that
+// match, so 1 is always the best one. A digit meant for the query is typed
+// after a backslash.
+const filterKeys = "123456789"
+
+// fuzzyScore reports whether every rune of query appears in target in order
+// (case-insensitive) and how good the match is. Consecutive runs, matches at
+// the start of a word, and a match at the very start score higher.
+func fuzzyScore(query, target string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+	q := []rune(strings.ToLower(query))
+	t := []rune(strings.ToLower(target))
+
+	score, qi, prev := 0, 0, -2
+	for ti := 0; ti < len(t) && qi < len(q); ti++ {
+		if t[ti] != q[qi] {
+			continue
+		}
+		switch {
+		case ti == 0:
+			score += 8
+		case !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]):
+			score += 3
+		}
+		if ti == prev+1 {
+			score += 6
+		}
+		score++
+		prev = ti
+		qi++
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+	// Prefer shorter targets when the match is otherwise equal.
+	return score*100 - len(t), true
+}
+
+// filterSessions returns the sessions matching query, best first. Names
+// count for more than the directory the session started in.
+func filterSessions(sessions []backend.Session, query string) []backend.Session {
+	type match struct {
+		s     backend.Session
+		score int
+	}
+	var matches []match
+	for _, s := range sessions {
+		best, ok := fuzzyScore(query, s.Name)
+		if ok {
+			best *= 2
+		}
+		if score, dirOK := fuzzyScore(query, s.StartedIn); dirOK && (!ok || score > best) {
+			best, ok = score, true
+		}
+		if ok {
+			matches = append(matches, match{s, best})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	out := make([]backend.Session, len(matches))
+	for i, m := range matches {
+		out[i] = m.s
+	}
+	return out
+}
+
+// filterState is the query being typed in filter mode.
+type filterState struct {
+	query   string
+	matches []backend.Session
+	cursor  int  // highlighted match; Enter attaches it
+	literal bool // a backslash was typed; the next digit joins the query
+}
+
+// handle applies one keypress (one rune, or one escape sequence). It returns
+// the resulting action and true when filter mode is done; ActionRetry means
+// cancelled. Enter attaches the highlighted match, which is the best one
+// until the arrows move it. A digit picks the match with that label, or
+// joins the query when it follows a backslash.
+func (f *filterState) handle(input []byte, sessions []backend.Session) (Action, bool) {
+	if len(input) == 0 {
+		return Action{}, false
+	}
+	key := input[0]
+	literal := f.literal
+	f.literal = false
+	if key == 27 && len(input) > 1 || key == 14 || key == 16 {
+		// Arrows and Ctrl-N/P move the highlight. J/K are query letters here.
+		if k := decodeKey(input); k.kind != keyChar && k.kind != keyUnknown {
+			f.cursor = moveCursor(f.cursor, min(len(f.matches), len(filterKeys)), len(filterKeys), k.kind)
+		}
+		return Action{}, false
+	}
+	switch {
+	case key == 3 || (len(input) == 1 && key == 27):
+		return Action{Type: ActionRetry}, true
+	case key == 13 || key == 10:
+		if f.cursor >= len(f.matches) {
+			return Action{}, false
+		}
+		return attachAction(f.matches[f.cursor]), true
+	case key == 127 || key == 8:
+		if literal {
+			return Action{}, false // backspace takes back the backslash
+		}
+		_, size := utf8.DecodeLastRuneInString(f.query)
+		f.query = f.query[:len(f.query)-size]
+	case key == 21: // Ctrl-U
+		f.query = ""
+	case key == '\\' && !literal:
+		f.literal = true
+		return Action{}, false
+	case !literal && strings.IndexByte(filterKeys, key) >= 0:
+		if idx := strings.IndexByte(filterKeys, key); idx < len(f.matches) {
+			return attachAction(f.matches[idx]), true
+		}
+		return Action{}, false
+	case key > 32 && key < 127, key >= utf8.RuneSelf && utf8.Valid(input):
+		f.query += string(input)
+	default:
+		return Action{}, false
+	}
+	f.matches = filterSessions(sessions, f.query)
+	f.cursor = 0
+	return Action{}, false
+}
+
+// splitTyped splits a read into single keys, since fast typing (or a paste)
+// can deliver several at once. Escape sequences stay whole, and so do UTF-8
+// characters; rest holds the start of one the read cut off.
+func splitTyped(input []byte) (keys [][]byte, rest []byte) {
+	if len(input) > 0 && input[0] == 27 {
+		return [][]byte{input}, nil
+	}
+	for len(input) > 0 {
+		if !utf8.FullRune(input) {
+			return keys, input
+		}
+		_, size := utf8.DecodeRune(input)
+		keys = append(keys, input[:size])
+		input = input[size:]
+	}
+	return keys, nil
+}
+
+func attachAction(s backend.Session) Action {
+	return Action{Type: ActionAttach, Name: s.Name, Backend: s.Backend, Host: s.Host}
+}
+
+// enterFilterMode lets the user type a fuzzy query, redrawing the matching
+// sessions after every key.
+func enterFilterMode(tty *os.File, sessions []backend.Session, currentSession string) (Action, error) {
+	f := &filterState{matches: sessions}
+	draw := func() { drawFilter(tty, f, currentSession) }
+
+	buf := make([]byte, 3)
+	var rest []byte
+	for {
+		draw()
+		n, err := readRaw(tty, buf, draw)
+		if err != nil {
+			return Action{}, err
+		}
+		var keys [][]byte
+		keys, rest = splitTyped(append(rest, buf[:n]...))
+		for _, input := range keys {
+			if action, done := f.handle(input, sessions); done {
+				if action.Type == ActionAttach {
+					fmt.Fprintf(tty, "\n  %s>%s %s%s%s\n\n", boldGrn, reset, boldWht, action.Name, reset)
+				}
+				return action, nil
+			}
+		}
+	}
+}
+
+// drawFilter renders filter mode. Lines end with \r\n so it can also be
+// drawn while the terminal is in raw mode.
+func drawFilter(tty *os.File, f *filterState, currentSession string) {
+	fmt.Fprint(tty, "\033[H\033[2J\r\n")
+	for i, s := range f.matches {
+		if i >= len(filterKeys) {
+			fmt.Fprintf(tty, "      %s+%d more%s\r\n", dim, len(f.matches)-i, reset)
+			break
+		}
+		indicator := fmt.Sprintf("%s.%s", dim, reset)
+		if s.Name == currentSession && s.Host == "" {
+			indicator = fmt.Sprintf("%s←%s", boldCyan, reset)
+		} else if s.Active {
+			indicator = fmt.Sprintf("%s*%s", boldGrn, reset)
+		}
+		row := fmt.Sprintf("%s%c%s  %s%s%s %s %s%s%s",
+			boldYel, filterKeys[i], reset,
+			boldWht, s.Name, reset,
+			indicator,
+			dim, truncatePath(s.StartedIn, 40), reset)
+		if i == f.cursor {
+			fmt.Fprintf(tty, "%s›%s %s\r\n", boldCyan, reset, highlight(row))
+		} else {
+			fmt.Fprintf(tty, "  %s\r\n", row)
+		}
+	}
+	if len(f.matches) == 0 {
+		fmt.Fprintf(tty, "  %sno matches%s\r\n", dim, reset)
+	}
+	fmt.Fprintf(tty, "\r\n  %senter%s %sattach%s  %s1-9%s %spick%s  %sesc%s %scancel%s\r\n\r\n",
+		boldGrn, reset, dim, reset,
+		boldYel, reset, dim, reset,
+		yellow, reset, dim, reset)
+	query := f.query
+	if f.literal {
+		query += `\`
+	}
+	fmt.Fprintf(tty, "  %s/%s %s", boldCyan, reset, query)
+}
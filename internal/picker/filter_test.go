@@ -0,0 +1,136 @@
+package picker
+
+import (
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	if _, ok := fuzzyScore("apsv", "api-server"); !ok {
+		t.Error("apsv should match api-server as a subsequence")
+	}
+	if _, ok := fuzzyScore("xyz", "api-server"); ok {
+		t.Error("xyz should not match api-server")
+	}
+	if _, ok := fuzzyScore("API", "api-server"); !ok {
+		t.Error("matching should ignore case")
+	}
+
+	prefix, _ := fuzzyScore("api", "api-server")
+	scattered, _ := fuzzyScore("api", "a-project-index")
+	if prefix <= scattered {
+		t.Errorf("prefix match (%d) should beat a scattered one (%d)", prefix, scattered)
+	}
+	word, _ := fuzzyScore("srv", "api-srv")
+	inner, _ := fuzzyScore("srv", "observer-v")
+	if word <= inner {
+		t.Errorf("word-start match (%d) should beat an inner one (%d)", word, inner)
+	}
+}
+
+func TestFilterSessionsRanksBestFirst(t *testing.T) {
+	sessions := []backend.Session{
+		{Name: "frontend", StartedIn: "/src/web"},
+		{Name: "api-server", StartedIn: "/src/api"},
+		{Name: "scratch", StartedIn: "/tmp"},
+		{Name: "notes", StartedIn: "/src/apidocs"},
+	}
+
+	got := filterSessions(sessions, "api")
+	if len(got) != 2 {
+		t.Fatalf("filterSessions() = %+v, want 2 matches", got)
+	}
+	if got[0].Name != "api-server" || got[1].Name != "notes" {
+		t.Errorf("order = %s, %s; name matches should rank above dir matches", got[0].Name, got[1].Name)
+	}
+
+	if all := filterSessions(sessions, ""); len(all) != len(sessions) {
+		t.Errorf("empty query should match everything, got %d", len(all))
+	}
+}
+
+func TestFilterStateHandle(t *testing.T) {
+	sessions := []backend.Session{{Name: "alpha"}, {Name: "beta"}, {Name: "alphabet"}}
+	f := &filterState{matches: sessions}
+
+	for _, k := range "bet" {
+		if _, done := f.handle([]byte{byte(k)}, sessions); done {
+			t.Fatalf("typing %q should not finish filter mode", k)
+		}
+	}
+	if f.query != "bet" || len(f.matches) != 2 || f.matches[0].Name != "beta" {
+		t.Fatalf("after typing: query %q, matches %+v", f.query, f.matches)
+	}
+
+	// Matches are re-keyed: 1 is the best match, 2 the second best.
+	action, done := f.handle([]byte{'2'}, sessions)
+	if !done || action.Type != ActionAttach || action.Name != "alphabet" {
+		t.Errorf("'2' = %+v, want attach alphabet", action)
+	}
+	action, done = f.handle([]byte{'1'}, sessions)
+	if !done || action.Type != ActionAttach || action.Name != "beta" {
+		t.Errorf("'1' = %+v, want attach the best match", action)
+	}
+
+	f.handle([]byte{127}, sessions)
+	if f.query != "be" {
+		t.Errorf("backspace left query %q", f.query)
+	}
+
+	action, done = f.handle([]byte{13}, sessions)
+	if !done || action.Name != "beta" {
+		t.Errorf("enter = %+v, want the top match", action)
+	}
+
+	// Down arrow moves the highlight; J is still a query letter.
+	if _, done := f.handle([]byte("\x1b[B"), sessions); done || f.query != "be" {
+		t.Fatalf("down arrow changed query to %q (done %v)", f.query, done)
+	}
+	action, done = f.handle([]byte{13}, sessions)
+	if !done || action.Name != "alphabet" {
+		t.Errorf("enter after down = %+v, want the highlighted match", action)
+	}
+
+	if action, done := f.handle([]byte{27}, sessions); !done || action.Type != ActionRetry {
+		t.Errorf("esc = %+v, want cancel", action)
+	}
+}
+
+func TestFilterStateQueryDigitsAndUnicode(t *testing.T) {
+	sessions := []backend.Session{{Name: "api"}, {Name: "api2"}, {Name: "café"}}
+	f := &filterState{matches: sessions}
+
+	for _, k := range [][]byte{{'a'}, {'\\'}, {'2'}} {
+		if _, done := f.handle(k, sessions); done {
+			t.Fatalf("%q should be part of the query", k)
+		}
+	}
+	if f.query != "a2" || len(f.matches) != 1 || f.matches[0].Name != "api2" {
+		t.Errorf("query %q matches %+v, want api2 only", f.query, f.matches)
+	}
+
+	// A backslash then backspace leaves the query as it was.
+	f.handle([]byte{'\\'}, sessions)
+	f.handle([]byte{127}, sessions)
+	f.handle([]byte{127}, sessions)
+	if f.query != "a" {
+		t.Errorf("query = %q after backslash and two backspaces, want a", f.query)
+	}
+
+	f = &filterState{matches: sessions}
+	keys, rest := splitTyped([]byte("fé\xc3"))
+	for _, k := range keys {
+		f.handle(k, sessions)
+	}
+	if f.query != "fé" || len(rest) != 1 {
+		t.Errorf("query %q rest %q, want fé with the cut-off byte kept", f.query, rest)
+	}
+	if len(f.matches) != 1 || f.matches[0].Name != "café" {
+		t.Errorf("matches = %+v, want café", f.matches)
+	}
+	f.handle([]byte{127}, sessions)
+	if f.query != "f" {
+		t.Errorf("backspace should remove a whole character, query = %q", f.query)
+	}
+}
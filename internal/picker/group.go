@@ -0,0 +1,121 @@
+package picker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/config"
+	"github.com/nerveband/zpick/internal/git"
+)
+
+// readGrouping returns whether grouping is on and the configured project roots.
+func readGrouping() (bool, []string) {
+	c, _ := config.Load()
+	return c.Group.Enabled, c.Group.Roots
+}
+
+// toggleGrouping flips group.enabled and returns the new value.
+func toggleGrouping(enabled bool) bool {
+	config.Update(func(c *config.Config) error {
+		c.Group.Enabled = !enabled
+		return nil
+	})
+	return !enabled
+}
+
+// rootCache caches groupRoot lookups for one run of the picker, by the
+// directory a session started in; each lookup stats every parent directory.
+type rootCache map[string]string
+
+// groupRoot returns the directory a session started in is grouped under: the
+// enclosing git repository, or else the top-level directory under one of the
+// configured project roots. It returns "" when neither applies.
+func (c rootCache) groupRoot(dir string, roots []string) string {
+	if dir == "" {
+		return ""
+	}
+	if root, ok := c[dir]; ok {
+		return root
+	}
+	root := git.Root(dir)
+	if root == "" {
+		root = underRoot(dir, roots)
+	}
+	c[dir] = root
+	return root
+}
+
+// underRoot returns root/<name> when dir is inside a project root such as
+// ~/src, so ~/src/api/cmd groups under ~/src/api.
+func underRoot(dir string, roots []string) string {
+	for _, root := range roots {
+		root = filepath.Clean(expandHome(root))
+		rel, err := filepath.Rel(root, dir)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		first, _, _ := strings.Cut(rel, string(filepath.Separator))
+		return filepath.Join(root, first)
+	}
+	return ""
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, path[1:])
+	}
+	return path
+}
+
+// groupSessions reorders the local sessions so each group's sessions are
+// together, groups in order of their first session, and ungrouped sessions
+// after them. Remote sessions keep their host headings and aren't grouped.
+// It returns each session's group root ("" for none), parallel to sessions,
+// or nil when nothing could be grouped.
+func (c rootCache) groupSessions(sessions []backend.Session, roots []string) []string {
+	local := 0
+	for local < len(sessions) && sessions[local].Host == "" {
+		local++
+	}
+
+	var order []string
+	members := map[string][]backend.Session{}
+	for _, s := range sessions[:local] {
+		root := c.groupRoot(s.StartedIn, roots)
+		if _, ok := members[root]; !ok && root != "" {
+			order = append(order, root)
+		}
+		members[root] = append(members[root], s)
+	}
+	if len(order) == 0 {
+		return nil
+	}
+	order = append(order, "")
+
+	groups := make([]string, 0, len(sessions))
+	i := 0
+	for _, root := range order {
+		for _, s := range members[root] {
+			sessions[i] = s
+			groups = append(groups, root)
+			i++
+		}
+	}
+	for range sessions[local:] {
+		groups = append(groups, "")
+	}
+	return groups
+}
+
+// groupHeading renders the heading above a group: the root's base name and
+// its path, or "other" for the ungrouped sessions.
+func groupHeading(root string) string {
+	if root == "" {
+		return fmt.Sprintf("  %sother%s", dim, reset)
+	}
+	return fmt.Sprintf("  %s%s%s %s%s%s", boldCyan, filepath.Base(root), reset, dim, truncatePath(root, 40), reset)
+}
@@ -0,0 +1,80 @@
+package picker
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+func TestGroupRoot(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	c := rootCache{}
+
+	repo := filepath.Join(home, "work", "api")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Join(repo, "cmd", "server"), 0o755)
+	os.MkdirAll(filepath.Join(home, "src", "notes", "drafts"), 0o755)
+
+	roots := []string{"~/src"}
+	tests := []struct {
+		dir, want string
+	}{
+		{filepath.Join(repo, "cmd", "server"), repo},
+		{repo, repo},
+		{filepath.Join(home, "src", "notes", "drafts"), filepath.Join(home, "src", "notes")},
+		{filepath.Join(home, "src"), ""},
+		{filepath.Join(home, "work"), ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := c.groupRoot(tt.dir, roots); got != tt.want {
+			t.Errorf("groupRoot(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestGroupSessions(t *testing.T) {
+	c := rootCache{"/r/api": "/r/api", "/r/api/web": "/r/api", "/r/db": "/r/db", "/tmp": ""}
+
+	sessions := []backend.Session{
+		{Name: "api", StartedIn: "/r/api"},
+		{Name: "scratch", StartedIn: "/tmp"},
+		{Name: "db", StartedIn: "/r/db"},
+		{Name: "web", StartedIn: "/r/api/web"},
+		{Name: "logs", Host: "devbox", StartedIn: "/r/api"},
+	}
+	groups := c.groupSessions(sessions, nil)
+
+	if got, want := sessionNames(sessions), []string{"api", "web", "db", "scratch", "logs"}; !slices.Equal(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+	if want := []string{"/r/api", "/r/api", "/r/db", "", ""}; !slices.Equal(groups, want) {
+		t.Errorf("groups = %q, want %q", groups, want)
+	}
+
+	// Keys follow the display order, headings go in between.
+	rows := strings.Join(sessionRows(sessions, pageRows{groups: groups}, "", -1), "\n")
+	for _, want := range []string{"api", "db", "other", "devbox"} {
+		if !strings.Contains(rows, want) {
+			t.Errorf("rows missing %q:\n%s", want, rows)
+		}
+	}
+	if i, j := strings.Index(rows, "2"+reset+"  "+boldWht+"web"), strings.Index(rows, "db"+reset+" "); i < 0 || i > j {
+		t.Errorf("web should be key 2, above the db heading:\n%s", rows)
+	}
+}
+
+func TestGroupSessionsFlatWhenNothingGroups(t *testing.T) {
+	c := rootCache{"/tmp": ""}
+	sessions := []backend.Session{{Name: "a", StartedIn: "/tmp"}, {Name: "b", StartedIn: "/tmp"}}
+	if groups := c.groupSessions(sessions, nil); groups != nil {
+		t.Errorf("groups = %q, want nil for a flat list", groups)
+	}
+}
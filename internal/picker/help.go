@@ -3,29 +3,29 @@ package picker
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/config"
+	"github.com/nerveband/zpick/internal/theme"
 	"golang.org/x/term"
 )
 
 // showHelpConfig renders the help/config screen on tty.
-// Handles 'b' to cycle backend, 'u' to toggle UDP. Esc returns to picker.
+// Handles 'b' to cycle backend, 'u' to toggle UDP, 'l' to toggle key mode,
+// 's' to cycle the sort order, 'g' to toggle grouping and 't' to cycle the
+// theme. Esc returns to picker.
 // Returns the (possibly changed) backend.
 func showHelpConfig(tty *os.File, b backend.Backend, version string) backend.Backend {
+	// Detection runs plugins, so it happens once here, not on every redraw.
+	available := backend.Detect()
 	for {
-		renderHelp(tty, b, version)
-
-		oldState, err := term.MakeRaw(int(tty.Fd()))
-		if err != nil {
-			return b
-		}
+		draw := func() { renderHelp(tty, b, available, version) }
+		draw()
 
 		buf := make([]byte, 3)
-		n, err := tty.Read(buf)
-		term.Restore(int(tty.Fd()), oldState)
-
+		n, err := readRaw(tty, buf, draw)
 		if err != nil {
 			return b
 		}
@@ -39,16 +39,24 @@ func showHelpConfig(tty *os.File, b backend.Backend, version string) backend.Bac
 
 		switch key {
 		case 'b':
-			b = cycleBackend(tty, b)
+			b = cycleBackend(tty, b, available)
 		case 'u':
 			toggleUDP(tty)
 		case 'l':
 			toggleKeyMode()
+		case 's':
+			cycleSortOrder(readSortOrder())
+		case 'g':
+			grouped, _ := readGrouping()
+			toggleGrouping(grouped)
+		case 't':
+			cycleTheme()
 		}
 	}
 }
 
-func renderHelp(tty *os.File, b backend.Backend, version string) {
+// renderHelp draws the help screen; available lists the detected backends.
+func renderHelp(tty *os.File, b backend.Backend, available []string, version string) {
 	// Clear screen
 	fmt.Fprint(tty, "\033[2J\033[H")
 
@@ -70,12 +78,14 @@ func renderHelp(tty *os.File, b backend.Backend, version string) {
 		fmt.Fprintf(tty, "    %sc%s       %-18s %s%-5s%s %s\n", magenta, reset, "custom name", cyan, "d", reset, "+date name")
 		fmt.Fprintf(tty, "    %sz%s       %-18s %s%-5s%s %s\n", magenta, reset, "pick dir (zoxide)", red, "k", reset, "kill session")
 		fmt.Fprintf(tty, "    %sh%s       %-18s %s%-5s%s %s\n", cyan, reset, "this screen", yellow, "esc", reset, "skip")
+		printHelpKeys(tty, actionKeys(b), "    %s%-7s%s %-18s", " %s%-5s%s %s")
 	} else {
 		fmt.Fprintf(tty, "    %s%s%s  attach session\n", boldYel, keyRange, reset)
 		fmt.Fprintf(tty, "    %senter%s  new session\n", boldGrn, reset)
 		fmt.Fprintf(tty, "    %sc%s  custom name   %sd%s  +date name\n", magenta, reset, cyan, reset)
 		fmt.Fprintf(tty, "    %sz%s  pick dir      %sk%s  kill session\n", magenta, reset, red, reset)
 		fmt.Fprintf(tty, "    %sh%s  this screen   %sesc%s  skip\n", cyan, reset, yellow, reset)
+		printHelpKeys(tty, actionKeys(b), "    %s%s%s  %-12s", "%s%s%s  %s")
 	}
 	fmt.Fprintln(tty)
 
@@ -84,10 +94,9 @@ func renderHelp(tty *os.File, b backend.Backend, version string) {
 	home, _ := os.UserHomeDir()
 	displayDir := strings.Replace(configDir, home, "~", 1)
 
-	fmt.Fprintf(tty, "  %sConfig%s %s%s/%s\n", boldWht, reset, dim, displayDir, reset)
+	fmt.Fprintf(tty, "  %sConfig%s %s%s/%s%s\n", boldWht, reset, dim, displayDir, config.FileName, reset)
 
 	// Backend
-	available := backend.Detect()
 	availStr := strings.Join(available, ", ")
 	if width >= 60 {
 		fmt.Fprintf(tty, "    %sb%s  backend    %s%-12s%s %s[%s]%s\n",
@@ -98,7 +107,7 @@ func renderHelp(tty *os.File, b backend.Backend, version string) {
 	}
 
 	// Guard
-	apps := readGuardApps(configDir)
+	apps := readGuardApps()
 	appsStr := strings.Join(apps, ", ")
 	fmt.Fprintf(tty, "    %s·%s  guard      %s%s%s\n", dim, reset, dim, appsStr, reset)
 	fmt.Fprintf(tty, "    %s%s  manage:    zp guard add/remove/list%s\n", dim, dim, reset)
@@ -128,16 +137,31 @@ func renderHelp(tty *os.File, b backend.Backend, version string) {
 	}
 	fmt.Fprintf(tty, "    %sl%s  keys       %s%-12s%s %s[%s]%s\n", magenta, reset, boldWht, keyMode, reset, dim, keyLabel, reset)
 
+	// Sort order
+	fmt.Fprintf(tty, "    %ss%s  sort       %s%s%s\n", magenta, reset, boldWht, readSortOrder(), reset)
+	groupStr := "off"
+	if grouped, _ := readGrouping(); grouped {
+		groupStr = "by repo"
+	}
+	fmt.Fprintf(tty, "    %sg%s  group      %s%s%s\n", magenta, reset, boldWht, groupStr, reset)
+
+	// Theme
+	c, _ := config.Load()
+	fmt.Fprintf(tty, "    %st%s  theme      %s%s%s\n", magenta, reset, boldWht, c.Theme.Name, reset)
+
 	fmt.Fprintln(tty)
 	fmt.Fprintf(tty, "  %s%s%s  %sgithub.com/nerveband/zpick%s\n", dim, version, reset, dim, reset)
 	fmt.Fprintf(tty, "  %sesc%s %sback%s\n", yellow, reset, dim, reset)
 }
 
-func cycleBackend(tty *os.File, current backend.Backend) backend.Backend {
-	available := backend.Detect()
+// cycleBackend switches to the backend after current among available, the
+// detected backends.
+func cycleBackend(tty *os.File, current backend.Backend, available []string) backend.Backend {
 	if len(available) < 2 {
 		return current
 	}
+	// With more than one backend installed, "all" merges them into one list.
+	available = append(slices.Clip(available), backend.AggregateName)
 
 	// Find current index and cycle to next
 	currentName := current.Name()
@@ -182,22 +206,56 @@ func toggleKeyMode() {
 	LoadKeyMode(next)
 }
 
-// readGuardApps reads guard.conf from the config dir.
-// Returns defaults if the file doesn't exist.
-func readGuardApps(configDir string) []string {
-	data, err := os.ReadFile(filepath.Join(configDir, "guard.conf"))
-	if err != nil {
-		return []string{"claude", "codex", "opencode"}
+// cycleTheme saves the next theme name and redraws with it.
+func cycleTheme() {
+	config.Update(func(c *config.Config) error {
+		i := slices.Index(config.ThemeNames, c.Theme.Name)
+		c.Theme.Name = config.ThemeNames[(i+1)%len(config.ThemeNames)]
+		return nil
+	})
+	LoadTheme(theme.Load())
+}
+
+// readGuardApps returns the guarded apps from the config file.
+func readGuardApps() []string {
+	c, _ := config.Load()
+	return c.Guard.Apps
+}
+
+// helpKey is an optional action key shown on the help screen.
+type helpKey struct {
+	key   string
+	color string
+	label string
+}
+
+// actionKeys returns the extra action keys: navigation and filtering plus
+// the uppercase actions the backend supports.
+func actionKeys(b backend.Backend) []helpKey {
+	keys := []helpKey{
+		{"↑↓ J K", cyan, "move cursor"}, {"/", cyan, "filter sessions"},
+		{"P", cyan, "preview session"}, {"< >", cyan, "prev/next page"},
+		{"M", red, "mark, kill/detach"}, {"F", magenta, "pin/unpin session"},
+		{"N", cyan, "note and #tags"},
 	}
-	var apps []string
-	for _, line := range strings.Split(string(data), "\n") {
-		line = strings.TrimSpace(line)
-		if line != "" && !strings.HasPrefix(line, "#") {
-			apps = append(apps, line)
-		}
+	if backend.Supports[backend.Renamer](b) {
+		keys = append(keys, helpKey{"R", magenta, "rename session"})
+	}
+	if backend.Supports[backend.Templater](b) {
+		keys = append(keys, helpKey{"T", magenta, "layout template"})
 	}
-	if len(apps) == 0 {
-		return []string{"claude", "codex", "opencode"}
+	keys = append(keys, helpKey{"W", magenta, "worktree session"})
+	return keys
+}
+
+// printHelpKeys prints keys two to a line, matching the fixed rows above.
+// Each format takes color, key, reset and label.
+func printHelpKeys(tty *os.File, keys []helpKey, left, right string) {
+	for i := 0; i < len(keys); i += 2 {
+		line := fmt.Sprintf(left, keys[i].color, keys[i].key, reset, keys[i].label)
+		if i+1 < len(keys) {
+			line += fmt.Sprintf(right, keys[i+1].color, keys[i+1].key, reset, keys[i+1].label)
+		}
+		fmt.Fprintln(tty, strings.TrimRight(line, " "))
 	}
-	return apps
 }
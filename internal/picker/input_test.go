@@ -15,7 +15,7 @@ func TestPickerActionForInput_AttachesAvailableSession(t *testing.T) {
 		{Name: "beta"},
 	}
 
-	action := pickerActionForInput([]byte{'b'}, sessions)
+	action := pickerActionForInput([]byte{'b'}, sessions, nil)
 	if action.Type != ActionAttach {
 		t.Fatalf("expected ActionAttach, got %v", action.Type)
 	}
@@ -36,29 +36,29 @@ func TestPickerActionForInput_OutOfRangeSessionKeyRetries(t *testing.T) {
 		{Name: "foxtrot"},
 	}
 
-	action := pickerActionForInput([]byte{'s'}, sessions)
+	action := pickerActionForInput([]byte{'s'}, sessions, nil)
 	if action.Type != ActionRetry {
 		t.Fatalf("expected ActionRetry for out-of-range key, got %v", action.Type)
 	}
 }
 
 func TestPickerActionForInput_InvalidKeyRetries(t *testing.T) {
-	action := pickerActionForInput([]byte{'!'}, nil)
+	action := pickerActionForInput([]byte{'!'}, nil, nil)
 	if action.Type != ActionRetry {
 		t.Fatalf("expected ActionRetry for invalid key, got %v", action.Type)
 	}
 }
 
 func TestPickerActionForInput_EscapeRequiresSingleByte(t *testing.T) {
-	if action := pickerActionForInput([]byte{27}, nil); action.Type != ActionEscape {
+	if action := pickerActionForInput([]byte{27}, nil, nil); action.Type != ActionEscape {
 		t.Fatalf("expected escape for bare esc, got %v", action.Type)
 	}
 
-	if action := pickerActionForInput([]byte{27, '[', 'A'}, nil); action.Type != ActionRetry {
+	if action := pickerActionForInput([]byte{27, '[', 'A'}, nil, nil); action.Type != ActionRetry {
 		t.Fatalf("expected retry for escape sequence, got %v", action.Type)
 	}
 
-	if action := pickerActionForInput([]byte{3}, nil); action.Type != ActionEscape {
+	if action := pickerActionForInput([]byte{3}, nil, nil); action.Type != ActionEscape {
 		t.Fatalf("expected escape for ctrl-c, got %v", action.Type)
 	}
 }
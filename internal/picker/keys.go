@@ -1,5 +1,15 @@
 package picker
 
+import (
+	"bytes"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/state"
+)
+
 const (
 	// numbersFirst is the default key sequence: digits 1-9, then letters (skipping 'c' and 'k').
 	numbersFirst = "123456789abdefghijlmnopqrstuvwxy"
@@ -9,6 +19,8 @@ const (
 
 // keyChars maps session indices to keypress characters.
 // Note: 'c' is reserved for custom name, 'k' is reserved for kill mode.
+// Uppercase letters are never session keys; they are used for extra actions
+// such as 'R' (rename).
 var keyChars = []byte(numbersFirst)
 
 // MaxSessions is the maximum number of sessions the picker can display.
@@ -42,3 +54,116 @@ func IndexForKey(key byte) (int, bool) {
 	}
 	return -1, false
 }
+
+// pageRows describes the rows of a page beyond the sessions themselves.
+type pageRows struct {
+	keys   []byte                // session key per row; nil means KeyForIndex(row)
+	groups []string              // group root per row when grouped, else nil
+	pinned int                   // leading rows that are pinned
+	absent map[string]bool       // pins with no running session
+	marked map[string]bool       // marked sessions by sessionID
+	branch map[string]branchInfo // repository state by StartedIn, if shown
+	notes  map[string]state.Note // by sessionID
+	width  int                   // columns a row may use; 0 means no limit
+}
+
+// rowForKey returns the row of a page of n rows whose key is key. keys is
+// the key per row, or nil when keys follow position.
+func rowForKey(key byte, keys []byte, n int) (int, bool) {
+	if keys == nil {
+		idx, ok := IndexForKey(key)
+		return idx, ok && idx < n
+	}
+	i := bytes.IndexByte(keys, key)
+	return i, i >= 0
+}
+
+// assignSlots gives each session, identified by ids, a position in the key
+// sequence. The first pinned sessions get the first keys; every other
+// session keeps the slot saved for it if that's still free, and the rest
+// take the lowest free slots, so a killed session's key goes to the next new
+// one. It returns nil when there are more sessions than keys.
+func assignSlots(ids []string, pinned int, saved state.Slots) []int {
+	if len(ids) > len(keyChars) {
+		return nil
+	}
+	slots := make([]int, len(ids))
+	used := make([]bool, len(keyChars))
+	for i := range ids {
+		slots[i] = -1
+		if i < pinned {
+			slots[i] = i
+			used[i] = true
+		}
+	}
+	for i, id := range ids[min(pinned, len(ids)):] {
+		if slot, ok := saved[id]; ok && slot >= pinned && slot < len(keyChars) && !used[slot] {
+			slots[pinned+i] = slot
+			used[slot] = true
+		}
+	}
+	free := 0
+	for i := range slots {
+		if slots[i] >= 0 {
+			continue
+		}
+		for used[free] {
+			free++
+		}
+		slots[i] = free
+		used[free] = true
+	}
+	return slots
+}
+
+// coveredBy returns whether a session's state key (see state.Key) would be
+// in the last listing of b's sessions and those on the reachable hosts, if
+// the session still existed. State for sessions outside the listing, such as
+// another backend's or that of an aggregate member whose listing failed, is
+// left alone.
+func coveredBy(b backend.Backend, hosts, unreachable []string) func(key string) bool {
+	var prefixes []string
+	failed := backend.Failed(b)
+	for _, m := range backend.Members(b) {
+		if !slices.Contains(failed, m.Name()) {
+			prefixes = append(prefixes, m.Name()+"/")
+		}
+	}
+	for _, h := range hosts {
+		if !slices.Contains(unreachable, h) {
+			prefixes = append(prefixes, h+":")
+		}
+	}
+	return func(key string) bool {
+		return slices.ContainsFunc(prefixes, func(p string) bool { return strings.HasPrefix(key, p) })
+	}
+}
+
+// stableSlots assigns key slots to sessions (see assignSlots) and saves
+// them for next time. The slots of covered sessions that are gone are
+// freed; those of sessions outside this listing (see coveredBy) are kept.
+func stableSlots(sessions []backend.Session, pinned int, backendName string, covered func(string) bool) []int {
+	saved := state.LoadSlots()
+	ids := make([]string, len(sessions))
+	for i, s := range sessions {
+		ids[i] = stateKey(s, backendName)
+	}
+	slots := assignSlots(ids, pinned, saved)
+	if slots == nil {
+		return nil
+	}
+
+	next := state.Slots{}
+	for id, slot := range saved {
+		if !covered(id) {
+			next[id] = slot
+		}
+	}
+	for i, id := range ids[pinned:] {
+		next[id] = slots[pinned+i]
+	}
+	if !maps.Equal(next, saved) {
+		state.SaveSlots(next)
+	}
+	return slots
+}
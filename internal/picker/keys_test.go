@@ -1,6 +1,15 @@
 package picker
 
-import "testing"
+import (
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/backend/fake"
+	"github.com/nerveband/zpick/internal/state"
+)
 
 func TestKeyForIndex(t *testing.T) {
 	tests := []struct {
@@ -112,3 +121,97 @@ func TestLoadKeyMode_LettersMaxSessions(t *testing.T) {
 		t.Errorf("expected 32 max sessions in letters mode, got %d", MaxSessions)
 	}
 }
+
+func TestUppercaseIsNeverASessionKey(t *testing.T) {
+	for _, mode := range []string{"numbers", "letters"} {
+		LoadKeyMode(mode)
+		for key := byte('A'); key <= 'Z'; key++ {
+			if _, ok := IndexForKey(key); ok {
+				t.Errorf("%s mode: uppercase %q must stay free for actions", mode, key)
+			}
+		}
+	}
+	LoadKeyMode("numbers")
+}
+
+func TestAssignSlots(t *testing.T) {
+	saved := state.Slots{"tmux/api": 2, "tmux/web": 0, "tmux/db": 2, "tmux/old": 5}
+
+	// main is pinned and takes slot 0 from web; api keeps 2, db collides
+	// with it and gets the lowest free slot, as do new sessions.
+	got := assignSlots([]string{"tmux/main", "tmux/api", "tmux/web", "tmux/db", "tmux/new"}, 1, saved)
+	if want := []int{0, 2, 1, 3, 4}; !slices.Equal(got, want) {
+		t.Errorf("assignSlots() = %v, want %v", got, want)
+	}
+
+	ids := make([]string, len(keyChars)+1)
+	for i := range ids {
+		ids[i] = fmt.Sprint("tmux/s", i)
+	}
+	if got := assignSlots(ids, 0, nil); got != nil {
+		t.Errorf("more sessions than keys should fall back to positions, got %v", got)
+	}
+}
+
+func TestStableSlotsSurviveKill(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	sessions := []backend.Session{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+	state.SaveSlots(state.Slots{"zellij/z": 1})
+	covered := coveredBy(fake.NewAt(t.TempDir()+"/fake.json"), nil, nil)
+
+	stableSlots(sessions, 0, "fake", covered)
+	if got := stableSlots([]backend.Session{sessions[0], sessions[2]}, 0, "fake", covered); !slices.Equal(got, []int{0, 2}) {
+		t.Errorf("after killing b: slots %v, want c to keep 2", got)
+	}
+	got := stableSlots([]backend.Session{{Name: "d"}, sessions[0], sessions[2]}, 0, "fake", covered)
+	if !slices.Equal(got, []int{1, 0, 2}) {
+		t.Errorf("new session d got slots %v, want b's freed slot", got)
+	}
+	if slot, ok := state.LoadSlots()["zellij/z"]; !ok || slot != 1 {
+		t.Error("another backend's slots should be kept")
+	}
+}
+
+// renamedFake is a fake backend under another name, to make up aggregates.
+type renamedFake struct {
+	*fake.Fake
+	name string
+}
+
+func (f renamedFake) Name() string { return f.name }
+
+func TestCoveredBySkipsFailedMembers(t *testing.T) {
+	dir := t.TempDir()
+	broken := dir + "/broken.json"
+	os.WriteFile(broken, []byte("not json"), 0o644)
+	b := backend.NewMulti([]backend.Backend{fake.NewAt(broken), renamedFake{fake.NewAt(dir + "/tmux.json"), "tmux"}})
+	if _, err := b.FastList(); err != nil {
+		t.Fatal(err)
+	}
+
+	covered := coveredBy(b, []string{"devbox", "gone"}, []string{"gone"})
+	for key, want := range map[string]bool{
+		"tmux/api":   true,
+		"fake/api":   false, // its listing failed, so api may still be running
+		"devbox:api": true,
+		"gone:api":   false,
+		"zellij/api": false,
+	} {
+		if got := covered(key); got != want {
+			t.Errorf("covered(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestRowForKey(t *testing.T) {
+	if i, ok := rowForKey('3', []byte("13"), 2); !ok || i != 1 {
+		t.Errorf("rowForKey(3) = %d, %v", i, ok)
+	}
+	if _, ok := rowForKey('2', []byte("13"), 2); ok {
+		t.Error("a key no row has should not match")
+	}
+	if i, ok := rowForKey('2', nil, 2); !ok || i != 1 {
+		t.Errorf("positional rowForKey(2) = %d, %v", i, ok)
+	}
+}
@@ -0,0 +1,85 @@
+package picker
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/state"
+)
+
+// sessionID identifies a session across redraws, even in the aggregate view
+// or when remote hosts share session names.
+func sessionID(s backend.Session) string {
+	return state.Key(s.Backend, s.Host, s.Name)
+}
+
+// enterMarkMode lets session keys toggle marks on v's sessions, across
+// pages, until 'k' (kill) or 'D' (detach) acts on all of them. Esc drops the
+// marks and goes back to the picker.
+func enterMarkMode(tty *os.File, b backend.Backend, v *view) (Action, error) {
+	v.marked = map[string]bool{}
+	detach := backend.Supports[backend.Detacher](b)
+	draw := func() {
+		renderPicker(tty, b, v)
+		n := len(v.markedSessions())
+		fmt.Fprintf(tty, "\n  %smark%s %s%d marked, keys toggle%s", boldRed, reset, dim, n, reset)
+		if n > 0 {
+			fmt.Fprintf(tty, "  %sk%s %skill%s", red, reset, dim, reset)
+			if detach {
+				fmt.Fprintf(tty, "  %sD%s %sdetach%s", yellow, reset, dim, reset)
+			}
+		}
+		fmt.Fprintf(tty, "  %sesc%s %sdone%s ", yellow, reset, dim, reset)
+	}
+
+	buf := make([]byte, 8)
+	for {
+		draw()
+		n, err := readRaw(tty, buf, draw)
+		fmt.Fprintln(tty)
+		if err != nil {
+			return Action{}, err
+		}
+		input := buf[:n]
+		switch k := decodeKey(input); {
+		case k.kind == keyLeft || k.kind == keyChar && k.ch == '<':
+			v.turnPage(-1)
+			continue
+		case k.kind == keyRight || k.kind == keyChar && k.ch == '>':
+			v.turnPage(1)
+			continue
+		case len(input) != 1:
+			continue
+		}
+
+		marked := len(v.markedSessions()) > 0
+		switch key := input[0]; {
+		case key == 27 || key == 3:
+			v.marked = nil
+			return Action{Type: ActionRetry}, nil
+		case key == 'k' && marked:
+			return Action{Type: ActionKillMarked}, nil
+		case key == 'D' && marked && detach:
+			return Action{Type: ActionDetachMarked}, nil
+		}
+		page, keys := v.pageRunning()
+		if idx, ok := rowForKey(input[0], keys, len(page)); ok {
+			id := sessionID(page[idx])
+			v.marked[id] = !v.marked[id]
+		}
+	}
+}
+
+// detachClients disconnects everyone attached to s, if its backend can.
+func detachClients(b backend.Backend, s backend.Session) error {
+	if s.Host != "" {
+		return fmt.Errorf("can't detach sessions on %s", s.Host)
+	}
+	owner := backend.Resolve(b, s.Backend)
+	d, ok := backend.As[backend.Detacher](owner)
+	if !ok {
+		return fmt.Errorf("%s can't detach clients", owner.Name())
+	}
+	return d.DetachClients(s.Name)
+}
@@ -0,0 +1,50 @@
+package picker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/backend/fake"
+)
+
+func TestMarkedSessions(t *testing.T) {
+	sessions := []backend.Session{
+		{Name: "api", Backend: "tmux"},
+		{Name: "api", Backend: "shpool"},
+		{Name: "web", Backend: "tmux"},
+		{Name: "api", Host: "devbox"},
+	}
+	v := &view{cursor: -1}
+	v.setSessions(sessions, nil)
+	v.marked = map[string]bool{sessionID(sessions[3]): true, sessionID(sessions[1]): true}
+
+	got := v.markedSessions()
+	if len(got) != 2 || got[0].Backend != "shpool" || got[1].Host != "devbox" {
+		t.Errorf("markedSessions() = %+v, want shpool/api then devbox:api in display order", got)
+	}
+
+	rows := sessionRows(sessions[:3], pageRows{marked: v.marked}, "", -1)
+	if strings.Contains(rows[0], "+") || !strings.Contains(rows[1], "+") || strings.Contains(rows[2], "+") {
+		t.Errorf("only marked rows should carry the mark: %q", rows)
+	}
+}
+
+func TestDetachClients(t *testing.T) {
+	f := fake.NewAt(t.TempDir() + "/fake.json")
+	if err := f.Save(&fake.State{Sessions: []backend.Session{{Name: "api", Active: true, Clients: 2}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := detachClients(f, backend.Session{Name: "api"}); err != nil {
+		t.Fatal(err)
+	}
+	st, _ := f.Load()
+	if st.Sessions[0].Active || len(st.Detached) != 1 {
+		t.Errorf("after detach: %+v", st)
+	}
+
+	if err := detachClients(f, backend.Session{Name: "api", Host: "devbox"}); err == nil {
+		t.Error("detaching a remote session should fail")
+	}
+}
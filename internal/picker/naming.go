@@ -1,25 +1,89 @@
 package picker
 
 import (
+	"cmp"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/config"
+	"github.com/nerveband/zpick/internal/git"
 )
 
-// CounterName generates a session name like "dirname" or "dirname-N".
-func CounterName(dir string, existing []backend.Session) string {
-	base := filepath.Base(dir)
+// Naming settings from the [naming] config section: the layout {date} uses
+// and the templates for Enter, 'd' and 'z'.
+var (
+	dateFormat     = "0102"
+	newTemplate    = "{repo}/{branch}"
+	dateTemplate   = "{repo}-{date}"
+	zoxideTemplate = "{repo}/{branch}"
+)
+
+// LoadNaming applies the [naming] config section. Empty settings keep their
+// defaults.
+func LoadNaming(c config.NamingConfig) {
+	d := config.Default().Naming
+	dateFormat = cmp.Or(c.DateFormat, d.DateFormat)
+	newTemplate = cmp.Or(c.New, d.New)
+	dateTemplate = cmp.Or(c.Date, d.Date)
+	zoxideTemplate = cmp.Or(c.Zoxide, d.Zoxide)
+}
+
+// CounterName generates the name Enter gives a session of b in dir, from
+// naming.new: by default "repo/branch" (just the directory's name outside a
+// repository), or "name-N" when that's taken.
+func CounterName(b backend.Backend, dir string, existing []backend.Session) string {
+	return uniqueName(b, newTemplate, dir, existing)
+}
+
+// ZoxideName generates the name 'z' gives a session in dir, from
+// naming.zoxide, resolving conflicts like CounterName.
+func ZoxideName(b backend.Backend, dir string, existing []backend.Session) string {
+	return uniqueName(b, zoxideTemplate, dir, existing)
+}
+
+// DateName generates a session name like "repo-MMDD" from naming.date.
+// Unless the template counts with {n}, an existing session of the same name
+// is reattached rather than numbered: it's today's session.
+func DateName(b backend.Backend, dir string, existing []backend.Session) string {
+	if strings.Contains(dateTemplate, "{n}") {
+		return uniqueName(b, dateTemplate, dir, existing)
+	}
+	return expandName(b, dateTemplate, dir, 0)
+}
+
+// WorktreeName returns the session name b gives the worktree of branch in
+// the repository whose main worktree is main: "api-feature-login", like the
+// directory zp puts a new worktree in, with any character b can't take
+// written as '-' (on tmux "release/v1.2" gives "api-release/v1-2").
+func WorktreeName(b backend.Backend, main, branch string) string {
+	return backend.SafeName(b, filepath.Base(main)+"-"+branch)
+}
+
+// uniqueName expands tmpl to a name no existing session has: by counting
+// {n} up from 1, or when the template has no {n}, by appending -2, -3, ...
+func uniqueName(b backend.Backend, tmpl, dir string, existing []backend.Session) string {
 	names := make(map[string]bool)
 	for _, s := range existing {
 		names[s.Name] = true
 	}
 
+	if strings.Contains(tmpl, "{n}") {
+		for n := 1; ; n++ {
+			if candidate := expandName(b, tmpl, dir, n); !names[candidate] {
+				return candidate
+			}
+		}
+	}
+
+	base := expandName(b, tmpl, dir, 0)
 	if !names[base] {
 		return base
 	}
-
 	for n := 2; ; n++ {
 		candidate := fmt.Sprintf("%s-%d", base, n)
 		if !names[candidate] {
@@ -28,8 +92,71 @@ func CounterName(dir string, existing []backend.Session) string {
 	}
 }
 
-// DateName generates a session name like "dirname-MMDD".
-func DateName(dir string) string {
-	base := filepath.Base(dir)
-	return fmt.Sprintf("%s-%s", base, time.Now().Format("0102"))
+// nameTrim are the separators trimmed from the ends of an expanded name.
+const nameTrim = "-_ /.:"
+
+// expandName fills in a naming template for a session of be in dir; n is
+// the value of {n}. Placeholders that come out empty (such as {branch}
+// outside a repository) leave no stray separators at the ends, and a
+// template that expands to nothing gives the directory name. Characters be
+// can't take in a name are written as '-', in placeholder values and in the
+// template alike: "{repo}/{branch}" gives "api/feature/login" on tmux and
+// "api-feature-login" on zmosh, whose names are socket file names.
+func expandName(be backend.Backend, tmpl, dir string, n int) string {
+	var b strings.Builder
+	rest := tmpl
+	for {
+		before, after, ok := strings.Cut(rest, "{")
+		b.WriteString(before)
+		if !ok {
+			break
+		}
+		field, tail, ok := strings.Cut(after, "}")
+		if !ok {
+			b.WriteString("{" + after)
+			break
+		}
+		b.WriteString(placeholder(field, dir, n))
+		rest = tail
+	}
+
+	name := strings.Trim(b.String(), nameTrim)
+	if name == "" {
+		name = filepath.Base(dir)
+	}
+	return backend.SafeName(be, name)
+}
+
+// placeholder returns the value of one {field} of a naming template.
+// Unknown fields are kept as written.
+func placeholder(field, dir string, n int) string {
+	name, layout, _ := strings.Cut(field, ":")
+	switch name {
+	case "dir":
+		return filepath.Base(dir)
+	case "parent":
+		return filepath.Base(filepath.Dir(dir))
+	case "repo":
+		if root := git.Root(dir); root != "" {
+			return filepath.Base(root)
+		}
+		return filepath.Base(dir)
+	case "branch":
+		if root := git.Root(dir); root != "" {
+			return git.Branch(root)
+		}
+		return ""
+	case "date":
+		return time.Now().Format(cmp.Or(layout, dateFormat))
+	case "host":
+		host, _ := os.Hostname()
+		host, _, _ = strings.Cut(host, ".")
+		return host
+	case "n":
+		if n > 0 {
+			return strconv.Itoa(n)
+		}
+		return ""
+	}
+	return "{" + field + "}"
 }
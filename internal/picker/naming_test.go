@@ -1,14 +1,24 @@
 package picker
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/backend/fake"
+	"github.com/nerveband/zpick/internal/backend/tmux"
+	"github.com/nerveband/zpick/internal/config"
 )
 
+// sock stands in for a backend that keeps a socket file per session, so
+// names can't contain '/'.
+var sock = fake.New()
+
 func TestCounterName_NoConflict(t *testing.T) {
-	name := CounterName("projects", nil)
+	name := CounterName(sock, "projects", nil)
 	if name != "projects" {
 		t.Errorf("expected 'projects', got '%s'", name)
 	}
@@ -18,7 +28,7 @@ func TestCounterName_WithConflict(t *testing.T) {
 	existing := []backend.Session{
 		{Name: "projects"},
 	}
-	name := CounterName("projects", existing)
+	name := CounterName(sock, "projects", existing)
 	if name != "projects-2" {
 		t.Errorf("expected 'projects-2', got '%s'", name)
 	}
@@ -30,21 +40,21 @@ func TestCounterName_MultipleConflicts(t *testing.T) {
 		{Name: "projects-2"},
 		{Name: "projects-3"},
 	}
-	name := CounterName("projects", existing)
+	name := CounterName(sock, "projects", existing)
 	if name != "projects-4" {
 		t.Errorf("expected 'projects-4', got '%s'", name)
 	}
 }
 
 func TestCounterName_FromPath(t *testing.T) {
-	name := CounterName("/Users/nerveband/Documents/GitHub/my-project", nil)
+	name := CounterName(sock, "/Users/nerveband/Documents/GitHub/my-project", nil)
 	if name != "my-project" {
 		t.Errorf("expected 'my-project', got '%s'", name)
 	}
 }
 
 func TestDateName(t *testing.T) {
-	name := DateName("/Users/nerveband/projects")
+	name := DateName(sock, "/Users/nerveband/projects", nil)
 	if !strings.HasPrefix(name, "projects-") {
 		t.Errorf("expected projects-MMDD format, got '%s'", name)
 	}
@@ -52,3 +62,62 @@ func TestDateName(t *testing.T) {
 		t.Errorf("expected projects-MMDD length, got '%s' (len=%d)", name, len(name))
 	}
 }
+
+func TestWorktreeName(t *testing.T) {
+	tests := []struct {
+		b                  backend.Backend
+		main, branch, want string
+	}{
+		{sock, "/src/api", "feature/login", "api-feature-login"},
+		{sock, "/src/api", "release/v1.2", "api-release-v1.2"},
+		{tmux.New(), "/src/api", "release/v1.2", "api-release/v1-2"},
+		{tmux.New(), "/src/api.v2", "fix:crash", "api-v2-fix-crash"},
+	}
+	for _, tt := range tests {
+		if got := WorktreeName(tt.b, tt.main, tt.branch); got != tt.want {
+			t.Errorf("WorktreeName(%s, %q, %q) = %q, want %q", tt.b.Name(), tt.main, tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestNamingTemplates(t *testing.T) {
+	defer LoadNaming(config.NamingConfig{})
+	repo := t.TempDir()
+	os.MkdirAll(filepath.Join(repo, ".git"), 0o755)
+	os.WriteFile(filepath.Join(repo, ".git", "HEAD"), []byte("ref: refs/heads/feature/login\n"), 0o644)
+	src := filepath.Join(repo, "src")
+	os.Mkdir(src, 0o755)
+
+	LoadNaming(config.NamingConfig{Zoxide: "{parent}-{dir}-{n}", Date: "{dir}-{date:2006}"})
+	if got, want := CounterName(tmux.New(), src, nil), filepath.Base(repo)+"/feature/login"; got != want {
+		t.Errorf("CounterName() on tmux = %q, want %q", got, want)
+	}
+	if got, want := CounterName(sock, src, nil), filepath.Base(repo)+"-feature-login"; got != want {
+		t.Errorf("CounterName() = %q, want %q", got, want)
+	}
+	if got, want := CounterName(sock, src, []backend.Session{{Name: filepath.Base(repo) + "-feature-login"}}), filepath.Base(repo)+"-feature-login-2"; got != want {
+		t.Errorf("CounterName() with a conflict = %q, want %q", got, want)
+	}
+	if got := ZoxideName(sock, "/w/api", []backend.Session{{Name: "w-api-1"}}); got != "w-api-2" {
+		t.Errorf("ZoxideName() = %q, want {n} counted past w-api-1", got)
+	}
+	if got, want := DateName(sock, "/w/api", nil), "api-"+time.Now().Format("2006"); got != want {
+		t.Errorf("DateName() = %q, want %q", got, want)
+	}
+
+	// Only the characters the backend can't take are rewritten, in values
+	// and in the template alike.
+	LoadNaming(config.NamingConfig{New: "{dir}:{date:01.02}"})
+	if got, want := CounterName(tmux.New(), "/w/release.1", nil), "release-1-"+time.Now().Format("01-02"); got != want {
+		t.Errorf("CounterName() on tmux = %q, want %q", got, want)
+	}
+	if got, want := CounterName(sock, "/w/release.1", nil), "release.1:"+time.Now().Format("01.02"); got != want {
+		t.Errorf("CounterName() = %q, want %q", got, want)
+	}
+
+	// Outside a repository {repo} is the directory and {branch} is empty.
+	LoadNaming(config.NamingConfig{})
+	if got := CounterName(tmux.New(), "/w/api", nil); got != "api" {
+		t.Errorf("CounterName() outside a repo = %q, want api", got)
+	}
+}
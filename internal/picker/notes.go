@@ -0,0 +1,70 @@
+package picker
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/state"
+)
+
+// sessionNotes drops the notes of covered sessions that are gone and
+// returns the notes of sessions, keyed by sessionID.
+func sessionNotes(sessions []backend.Session, backendName string, covered func(string) bool) map[string]state.Note {
+	listed := map[string]bool{}
+	for _, s := range sessions {
+		listed[stateKey(s, backendName)] = true
+	}
+	notes := state.PruneNotes(func(key string) bool { return covered(key) && !listed[key] })
+
+	byID := map[string]state.Note{}
+	for _, s := range sessions {
+		if n, ok := notes[stateKey(s, backendName)]; ok {
+			byID[sessionID(s)] = n
+		}
+	}
+	return byID
+}
+
+// enterNoteMode asks which session on v's page to write a note for.
+func enterNoteMode(tty *os.File, v *view, redraw func()) (Action, error) {
+	prompt := func() {
+		fmt.Fprintf(tty, "\n  %snote%s %swhich session?%s ", cyan, reset, dim, reset)
+	}
+	prompt()
+
+	buf := make([]byte, 3)
+	n, err := readRaw(tty, buf, func() { redraw(); prompt() })
+	fmt.Fprintln(tty)
+	if err != nil {
+		return Action{}, err
+	}
+	sessions, keys := v.pageRunning()
+	if n == 1 {
+		if idx, ok := rowForKey(buf[0], keys, len(sessions)); ok {
+			s := sessions[idx]
+			return Action{Type: ActionNote, Name: s.Name, Backend: s.Backend, Host: s.Host}, nil
+		}
+	}
+	return Action{Type: ActionNote}, nil // cancelled or invalid key, redraw picker
+}
+
+// promptNote reads a new note for the session with the given state key.
+// Words starting with # are tags; an empty note removes it.
+func promptNote(tty *os.File, key, name string, current state.Note, redraw func()) {
+	prompt := func() {
+		if !current.Empty() {
+			fmt.Fprintf(tty, "  %snow: %s%s\n", dim, current, reset)
+		}
+		fmt.Fprintf(tty, "  %snote%s %s%s%s %s(#tags, empty clears):%s ", cyan, reset, boldWht, name, reset, dim, reset)
+	}
+	prompt()
+	text, ok := readLineRaw(tty, func() { redraw(); fmt.Fprintln(tty); prompt() })
+	if !ok {
+		fmt.Fprintf(tty, "  %scancelled%s\n", dim, reset)
+		return
+	}
+	if err := state.SetNote(key, state.ParseNote(text)); err != nil {
+		fmt.Fprintf(tty, "  %sfailed: %v%s\n", dim, err, reset)
+	}
+}
@@ -0,0 +1,51 @@
+package picker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/backend/fake"
+	"github.com/nerveband/zpick/internal/state"
+)
+
+func TestSessionNotesPrunesGone(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	for _, key := range []string{"fake/api", "fake/gone", "zellij/z", "devbox:remote", "vm1:remote"} {
+		state.SetNote(key, state.ParseNote("note for "+key))
+	}
+	covered := coveredBy(fake.NewAt(t.TempDir()+"/fake.json"), []string{"devbox", "vm1"}, []string{"vm1"})
+
+	sessions := []backend.Session{{Name: "api"}, {Name: "web"}}
+	notes := sessionNotes(sessions, "fake", covered)
+	if n := notes[sessionID(sessions[0])]; n.Text != "note for fake/api" || len(notes) != 1 {
+		t.Errorf("sessionNotes() = %v, want api's note only", notes)
+	}
+
+	saved := state.LoadNotes()
+	if _, ok := saved["fake/gone"]; ok {
+		t.Error("a gone session's note should be dropped")
+	}
+	if _, ok := saved["devbox:remote"]; ok {
+		t.Error("a gone session on a reachable host should be dropped")
+	}
+	for _, key := range []string{"zellij/z", "vm1:remote"} {
+		if _, ok := saved[key]; !ok {
+			t.Errorf("%s is outside the listing and should be kept", key)
+		}
+	}
+}
+
+func TestSessionRowsNote(t *testing.T) {
+	s := backend.Session{Name: "api", StartedIn: "/srv/api"}
+	notes := map[string]state.Note{sessionID(s): state.ParseNote("waiting on CI #prod")}
+
+	row := sessionRows([]backend.Session{s}, pageRows{notes: notes}, "", -1)[0]
+	if !strings.Contains(row, "waiting on CI #prod") {
+		t.Errorf("row should show the note: %q", row)
+	}
+	row = sessionRows([]backend.Session{s}, pageRows{notes: notes, width: 30}, "", -1)[0]
+	if strings.Contains(row, "waiting") || !strings.Contains(row, "/srv/api") {
+		t.Errorf("a narrow row should drop the note before the dir: %q", row)
+	}
+}
@@ -1,33 +1,57 @@
 package picker
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"os/exec"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/config"
+	"github.com/nerveband/zpick/internal/project"
+	"github.com/nerveband/zpick/internal/remote"
+	"github.com/nerveband/zpick/internal/state"
 	"github.com/nerveband/zpick/internal/switcher"
+	"github.com/nerveband/zpick/internal/theme"
 	"golang.org/x/term"
 )
 
-// ANSI color codes
-const (
-	reset    = "\033[0m"
-	dim      = "\033[2m"
-	red      = "\033[31m"
-	cyan     = "\033[36m"
-	green    = "\033[32m"
-	yellow   = "\033[33m"
-	magenta  = "\033[35m"
-	boldRed  = "\033[1;31m"
-	boldCyan = "\033[1;36m"
-	boldGrn  = "\033[1;32m"
-	boldYel  = "\033[1;33m"
-	boldWht  = "\033[1;97m"
+// ANSI styles, set from the theme by LoadTheme.
+var (
+	reset    string
+	dim      string
+	red      string
+	cyan     string
+	green    string
+	yellow   string
+	magenta  string
+	boldRed  string
+	boldCyan string
+	boldGrn  string
+	boldYel  string
+	boldWht  string
+	reverse  string
 )
 
+func init() {
+	LoadTheme(theme.Default)
+}
+
+// LoadTheme sets the styles every picker screen draws with.
+func LoadTheme(p theme.Palette) {
+	reset, dim, reverse = p.Reset, p.Dim, p.Reverse
+	red, cyan, green, yellow, magenta = p.Red, p.Cyan, p.Green, p.Yellow, p.Magenta
+	boldRed, boldCyan, boldGrn, boldYel, boldWht = p.BoldRed, p.BoldCyan, p.BoldGreen, p.BoldYellow, p.BoldWhite
+}
+
 type ActionType int
 
 const (
@@ -38,14 +62,24 @@ const (
 	ActionZoxide
 	ActionKill
 	ActionKillAll
+	ActionKillMarked
+	ActionDetachMarked
+	ActionRename
+	ActionPin
+	ActionNote
+	ActionTemplate
+	ActionWorktree
 	ActionHelp
 	ActionRetry
 	ActionEscape
 )
 
 type Action struct {
-	Type ActionType
-	Name string
+	Type    ActionType
+	Name    string
+	Backend string // owning backend in the aggregate view
+	Host    string // remote host for sessions reached over ssh
+	Dir     string // where a new session starts (worktrees)
 }
 
 // Run is the main interactive picker loop.
@@ -58,8 +92,15 @@ func Run(b backend.Backend, version string) (string, error) {
 		currentSession = b.CurrentSessionName()
 	}
 
-	// Load key mode preference (letters-first or numbers-first)
+	// Load key mode preference (letters-first or numbers-first) and naming
 	LoadKeyMode(backend.ReadKeyMode())
+	var hosts []string
+	if cfg, err := config.Load(); err == nil {
+		LoadNaming(cfg.Naming)
+		LoadPreview(cfg.Preview)
+		hosts = cfg.Remote.Hosts
+	}
+	LoadTheme(theme.Load())
 
 	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
 	if err != nil {
@@ -74,44 +115,93 @@ func Run(b backend.Backend, version string) (string, error) {
 		return "", nil
 	}
 
+	// Remote hosts are slow to query, so they're listed once and again only
+	// after a kill.
+	var remoteSessions []backend.Session
+	var unreachable []string
+	refreshRemote := len(hosts) > 0
+	v := &view{currentSession: currentSession, cursor: -1, sortOrder: readSortOrder()}
+	v.grouped, v.groupRoots = readGrouping()
+	v.pins = readPins()
+	gitStatus := readGitStatus()
+	var branches branchCache
+	groupCache := rootCache{}
+	// redraw repaints the picker when the terminal is resized under one of
+	// the prompts shown after it.
+	redraw := func() { renderPicker(tty, b, v) }
+
 	for {
 		sessions, err := b.FastList()
 		if err != nil {
 			return "", fmt.Errorf("failed to list sessions: %w", err)
 		}
+		if refreshRemote {
+			remoteSessions, unreachable = listRemote(tty, hosts)
+			refreshRemote = false
+		}
+		sessions = append(sessions, remoteSessions...)
+		hist := state.LoadHistory()
+		fillCreated(sessions, b.Name(), hist)
+		sortSessions(sessions, v.sortOrder, b.Name(), hist)
+		v.groups = nil
+		if v.grouped {
+			v.groups = groupCache.groupSessions(sessions, v.groupRoots)
+		}
+		var listed []backend.Session
+		listed, v.groups, v.absent = pinSessions(sessions, v.groups, v.pins)
+		v.setSessions(listed, unreachable)
+		covered := coveredBy(b, hosts, unreachable)
+		v.slots = stableSlots(listed, len(v.pins), b.Name(), covered)
+		v.notes = sessionNotes(listed, b.Name(), covered)
+		v.branches = nil
+		if gitStatus {
+			v.branches = branches.get(listed)
+		}
 
-		action, err := showPicker(tty, b, sessions, currentSession)
+		action, err := showPicker(tty, b, v)
 		if err != nil {
 			return "", err
 		}
 
 		switch action.Type {
 		case ActionAttach:
+			remember(b, action.Backend, action.Host, action.Name, false)
 			if inSession {
-				switcher.Write(switcher.Target{Action: "attach", Name: action.Name})
+				switcher.Write(switcher.Target{Action: "attach", Name: action.Name, Backend: action.Backend, Host: action.Host})
 				return b.DetachCommand(), nil
 			}
-			return sessionExec(b, action.Name, ""), nil
+			if action.Host != "" {
+				return remote.AttachCommand(action.Host, action.Name), nil
+			}
+			return sessionExec(backend.Resolve(b, action.Backend), action.Name, ""), nil
 		case ActionNew:
 			cwd, _ := os.Getwd()
-			name := CounterName(cwd, sessions)
+			if p, _ := project.Find(cwd); p != nil {
+				if !p.Trusted() && !confirmProject(tty, p, redraw) {
+					continue
+				}
+				return startProject(tty, b, p, inSession), nil
+			}
+			name := CounterName(b, cwd, sessions)
+			remember(b, "", "", name, true)
 			fmt.Fprintf(tty, "\n  %s>%s %s%s%s\n\n", boldGrn, reset, boldWht, name, reset)
 			if inSession {
 				switcher.Write(switcher.Target{Action: "new", Name: name})
 				return b.DetachCommand(), nil
 			}
-			return sessionExec(b, name, ""), nil
+			return sessionExec(backend.Resolve(b, ""), name, ""), nil
 		case ActionNewDate:
 			cwd, _ := os.Getwd()
-			name := DateName(cwd)
+			name := DateName(b, cwd, sessions)
+			remember(b, "", "", name, true)
 			fmt.Fprintf(tty, "\n  %s>%s %s%s%s\n\n", boldGrn, reset, boldWht, name, reset)
 			if inSession {
 				switcher.Write(switcher.Target{Action: "new", Name: name})
 				return b.DetachCommand(), nil
 			}
-			return sessionExec(b, name, ""), nil
+			return sessionExec(backend.Resolve(b, ""), name, ""), nil
 		case ActionCustom:
-			cmd, err := handleCustom(tty, b, sessions, inSession)
+			cmd, err := handleCustom(tty, b, sessions, inSession, redraw)
 			if err != nil {
 				return "", err
 			}
@@ -124,28 +214,116 @@ func Run(b backend.Backend, version string) (string, error) {
 			if err != nil || dir == "" {
 				continue
 			}
-			name := CounterName(dir, sessions)
+			name := ZoxideName(b, dir, sessions)
+			remember(b, "", "", name, true)
 			fmt.Fprintf(tty, "\n  %s>%s %s%s%s %s%s%s\n\n", boldGrn, reset, boldWht, name, reset, dim, dir, reset)
 			if inSession {
 				switcher.Write(switcher.Target{Action: "new", Name: name, Dir: dir})
 				return b.DetachCommand(), nil
 			}
-			return sessionExec(b, name, fmt.Sprintf("cd %q", dir)), nil
+			return sessionExec(backend.Resolve(b, ""), name, "cd "+backend.ShellQuote(dir)), nil
+		case ActionWorktree:
+			if action.Name == "" {
+				continue
+			}
+			// The worktree may already have its session; attach to that one,
+			// wherever it runs, rather than recording a new one.
+			i := slices.IndexFunc(sessions, func(s backend.Session) bool { return s.Name == action.Name && s.Host == "" })
+			if i >= 0 {
+				action.Backend = sessions[i].Backend
+			}
+			remember(b, action.Backend, "", action.Name, i < 0)
+			fmt.Fprintf(tty, "\n  %s>%s %s%s%s %s%s%s\n\n", boldGrn, reset, boldWht, action.Name, reset, dim, action.Dir, reset)
+			if inSession {
+				if i >= 0 {
+					switcher.Write(switcher.Target{Action: "attach", Name: action.Name, Backend: action.Backend})
+				} else {
+					switcher.Write(switcher.Target{Action: "new", Name: action.Name, Dir: action.Dir})
+				}
+				return b.DetachCommand(), nil
+			}
+			return sessionExec(backend.Resolve(b, action.Backend), action.Name, "cd "+backend.ShellQuote(action.Dir)), nil
 		case ActionKill:
 			if action.Name == "" {
 				continue // no session selected, redraw
 			}
-			if err := confirmAndKill(tty, b, action.Name); err != nil {
+			refreshRemote = action.Host != ""
+			if err := confirmAndKill(tty, killFunc(b, action.Backend, action.Host), action.Name, redraw); err != nil {
 				fmt.Fprintf(tty, "  %sfailed: %v%s\n", dim, err, reset)
 			} else {
 				fmt.Fprintf(tty, "  %skilled%s %s%s%s\n", boldRed, reset, boldWht, action.Name, reset)
 			}
 			continue
 		case ActionKillAll:
-			confirmAndKillAll(tty, b, sessions)
+			confirmAndKillAll(tty, b, sessions, redraw)
+			continue
+		case ActionKillMarked:
+			marked := v.markedSessions()
+			question := fmt.Sprintf("%skill %d marked sessions?%s", boldRed, len(marked), reset)
+			confirmAndApply(tty, question, marked, fmt.Sprintf("%skilled%s", boldRed, reset), func(s backend.Session) error {
+				return killFunc(b, s.Backend, s.Host)(s.Name)
+			}, redraw)
+			refreshRemote = slices.ContainsFunc(marked, func(s backend.Session) bool { return s.Host != "" })
+			v.marked = nil
+			continue
+		case ActionDetachMarked:
+			marked := v.markedSessions()
+			question := fmt.Sprintf("%sdetach everyone from %d marked sessions?%s", yellow, len(marked), reset)
+			confirmAndApply(tty, question, marked, fmt.Sprintf("%sdetached%s", yellow, reset), func(s backend.Session) error {
+				return detachClients(b, s)
+			}, redraw)
+			v.marked = nil
+			continue
+		case ActionRename:
+			if action.Name == "" {
+				continue
+			}
+			if action.Host != "" {
+				fmt.Fprintf(tty, "  %scan't rename sessions on %s%s\n", dim, action.Host, reset)
+				time.Sleep(800 * time.Millisecond)
+				continue
+			}
+			promptAndRename(tty, backend.Resolve(b, action.Backend), action.Name, sessions, redraw)
+			continue
+		case ActionPin:
+			if action.Name == "" {
+				continue
+			}
+			if action.Host != "" {
+				fmt.Fprintf(tty, "  %scan't pin sessions on %s%s\n", dim, action.Host, reset)
+				time.Sleep(800 * time.Millisecond)
+				continue
+			}
+			if pins, err := togglePin(action.Name); err != nil {
+				fmt.Fprintf(tty, "  %sfailed: %v%s\n", dim, err, reset)
+				time.Sleep(800 * time.Millisecond)
+			} else {
+				v.pins = pins
+			}
+			continue
+		case ActionNote:
+			if action.Name == "" {
+				continue
+			}
+			key := state.Key(backend.Resolve(b, action.Backend).Name(), action.Host, action.Name)
+			promptNote(tty, key, action.Name, state.LoadNotes()[key], redraw)
 			continue
+		case ActionTemplate:
+			if action.Name == "" {
+				continue
+			}
+			cwd, _ := os.Getwd()
+			cmd, err := startTemplate(tty, b, action.Name, CounterName(b, cwd, sessions), cwd, inSession)
+			if err != nil {
+				fmt.Fprintf(tty, "  %sfailed: %v%s\n", dim, err, reset)
+				time.Sleep(800 * time.Millisecond)
+				continue
+			}
+			return cmd, nil
 		case ActionHelp:
 			b = showHelpConfig(tty, b, version)
+			v.sortOrder = readSortOrder()
+			v.grouped, v.groupRoots = readGrouping()
 			continue
 		case ActionRetry:
 			continue
@@ -155,112 +333,514 @@ func Run(b backend.Backend, version string) (string, error) {
 	}
 }
 
-func showPicker(tty *os.File, b backend.Backend, sessions []backend.Session, currentSession string) (Action, error) {
-	fmt.Fprint(tty, "\033[H\033[2J") // clear screen
+func showPicker(tty *os.File, b backend.Backend, v *view) (Action, error) {
+	draw := func() { renderPicker(tty, b, v) }
+	draw()
+
+	buf := make([]byte, 8)
+	n, err := readRaw(tty, buf, draw)
 	fmt.Fprintln(tty)
+	if err != nil {
+		return Action{}, err
+	}
+
+	sessions, currentSession := v.running(), v.currentSession
+	page := v.pageSessions()
+	input := buf[:n]
+	switch k := decodeKey(input); {
+	case k.kind == keyUnknown:
+		return Action{Type: ActionRetry}, nil
+	case k.kind == keyLeft || k.kind == keyChar && k.ch == '<':
+		v.turnPage(-1)
+		return Action{Type: ActionRetry}, nil
+	case k.kind == keyRight || k.kind == keyChar && k.ch == '>':
+		v.turnPage(1)
+		return Action{Type: ActionRetry}, nil
+	case k.kind != keyChar:
+		v.cursor = moveCursor(v.cursor, len(page), cursorPageSize, k.kind)
+		return Action{Type: ActionRetry}, nil
+	}
+	if s, ok := v.selected(); ok && len(input) == 1 {
+		// With a highlighted row, Enter and the per-session actions apply to
+		// it instead of asking for a key; Esc drops the highlight.
+		switch input[0] {
+		case 13, 10:
+			fmt.Fprintf(tty, "\n  %s>%s %s%s%s\n\n", boldGrn, reset, boldWht, s.Name, reset)
+			return attachAction(s), nil
+		case 27:
+			v.cursor = -1
+			return Action{Type: ActionRetry}, nil
+		case 'F':
+			return Action{Type: ActionPin, Name: s.Name, Backend: s.Backend, Host: s.Host}, nil
+		}
+		if v.placeholder(v.cursor) && slices.Contains([]byte("kRPN"), input[0]) {
+			return Action{Type: ActionRetry}, nil // nothing running to act on
+		}
+		switch input[0] {
+		case 'k':
+			return Action{Type: ActionKill, Name: s.Name, Backend: s.Backend, Host: s.Host}, nil
+		case 'R':
+			if backend.Supports[backend.Renamer](b) {
+				return Action{Type: ActionRename, Name: s.Name, Backend: s.Backend, Host: s.Host}, nil
+			}
+		case 'P':
+			return showPreview(tty, b, s)
+		case 'N':
+			return Action{Type: ActionNote, Name: s.Name, Backend: s.Backend, Host: s.Host}, nil
+		}
+	}
+	if len(input) == 1 && input[0] == 'k' {
+		return enterKillMode(tty, v, draw)
+	}
+	if len(input) == 1 && input[0] == 'M' && len(sessions) > 0 {
+		return enterMarkMode(tty, b, v)
+	}
+	if len(input) == 1 && input[0] == 'F' && len(page) > 0 {
+		return enterPinMode(tty, v, draw)
+	}
+	if len(input) == 1 && input[0] == 'N' && len(sessions) > 0 {
+		return enterNoteMode(tty, v, draw)
+	}
+	if len(input) == 1 && input[0] == 'G' && len(sessions) > 1 {
+		v.grouped = toggleGrouping(v.grouped)
+		return Action{Type: ActionRetry}, nil
+	}
+	if len(input) == 1 && input[0] == 'S' && len(sessions) > 1 {
+		v.sortOrder = cycleSortOrder(v.sortOrder)
+		return Action{Type: ActionRetry}, nil
+	}
+	if len(input) == 1 && input[0] == 'R' && backend.Supports[backend.Renamer](b) {
+		return enterRenameMode(tty, v, draw)
+	}
+	if len(input) == 1 && input[0] == 'W' {
+		return enterWorktreeMode(tty, b, draw)
+	}
+	if len(input) == 1 && input[0] == 'T' && backend.Supports[backend.Templater](b) {
+		return enterTemplateMode(tty, draw)
+	}
+	if len(input) == 1 && input[0] == 'P' && len(sessions) > 0 {
+		running, keys := v.pageRunning()
+		return enterPreviewMode(tty, b, running, keys, draw)
+	}
+	if len(input) == 1 && input[0] == '/' && len(sessions) > 1 {
+		return enterFilterMode(tty, sessions, currentSession)
+	}
+
+	action := pickerActionForInput(input, page, v.pageKeys())
+	if action.Type == ActionAttach {
+		fmt.Fprintf(tty, "\n  %s>%s %s%s%s\n\n", boldGrn, reset, boldWht, action.Name, reset)
+	}
+	return action, nil
+}
+
+// renderPicker draws the picker screen for v, laid out for the terminal's
+// current size, ending at the input prompt. The page holds as many sessions
+// as fit under the headings and footer drawn with them.
+func renderPicker(tty *os.File, b backend.Backend, v *view) {
+	width, height := termSize(tty)
+	pane := previewPaneWidth(tty)
+	v.setPageSize(fitPageSize(v, height, func(trial *view) int {
+		var screen bytes.Buffer
+		drawPicker(&screen, b, trial, width, pane, blankPreview)
+		return strings.Count(screen.String(), "\n") + 1
+	}))
+	drawPicker(tty, b, v, width, pane, func(s backend.Session, n int) []string {
+		return previewLines(b, s, n)
+	})
+}
+
+// blankPreview stands in for previewLines when only the height of the
+// screen matters: n lines, the most previewLines returns.
+func blankPreview(s backend.Session, n int) []string {
+	return make([]string, n)
+}
+
+// drawPicker writes the picker screen for v to w, width columns wide (0 means
+// no limit) with a preview pane pane columns wide (0 means none) filled in
+// by preview.
+func drawPicker(w io.Writer, b backend.Backend, v *view, width, pane int, preview func(backend.Session, int) []string) {
+	sessions, currentSession, unreachable := v.sessions, v.currentSession, v.unreachable
+	page := v.pageSessions()
+	running, _ := v.pageRunning()
+
+	fmt.Fprint(w, "\033[H\033[2J") // clear screen
+	fmt.Fprintln(w)
 
 	if len(sessions) > 0 {
+		count := len(v.running())
 		plural := ""
-		if len(sessions) > 1 {
+		if count != 1 {
 			plural = "s"
 		}
+		pageLabel := ""
+		if label, ok := sortLabels[v.sortOrder]; ok {
+			pageLabel = fmt.Sprintf("  %s%s%s", dim, label, reset)
+		}
+		if v.pages() > 1 {
+			pageLabel += fmt.Sprintf("  %spage %d/%d%s", boldWht, v.page+1, v.pages(), reset)
+		}
 		if currentSession != "" {
-			fmt.Fprintf(tty, "  %s%s%s %s%d session%s%s  %s(in: %s ←)%s\n\n",
-				boldCyan, b.Name(), reset, dim, len(sessions), plural, reset,
+			fmt.Fprintf(w, "  %s%s%s %s%d session%s%s%s  %s(in: %s ←)%s\n\n",
+				boldCyan, b.Name(), reset, dim, count, plural, reset, pageLabel,
 				dim, currentSession, reset)
 		} else {
-			fmt.Fprintf(tty, "  %s%s%s %s%d session%s%s\n\n", boldCyan, b.Name(), reset, dim, len(sessions), plural, reset)
+			fmt.Fprintf(w, "  %s%s%s %s%d session%s%s%s\n\n", boldCyan, b.Name(), reset, dim, count, plural, reset, pageLabel)
 		}
 
-		for i, s := range sessions {
-			if i >= MaxSessions {
-				break
-			}
-			indicator := fmt.Sprintf("%s.%s", dim, reset)
-			if s.Name == currentSession {
-				indicator = fmt.Sprintf("%s←%s", boldCyan, reset)
-			} else if s.Active {
-				indicator = fmt.Sprintf("%s*%s", boldGrn, reset)
+		rowWidth := width
+		if pane > 0 {
+			rowWidth = width - pane - 4
+		}
+		info := v.pageRows()
+		info.width = rowWidth
+		rows := sessionRows(page, info, currentSession, v.cursor)
+		if pane > 0 && len(running) > 0 {
+			hovered := running[0]
+			if s, ok := v.selected(); ok && !v.placeholder(v.cursor) {
+				hovered = s
 			}
-			dir := truncatePath(s.StartedIn, 40)
-			fmt.Fprintf(tty, "  %s%c%s  %s%s%s %s %s%s%s\n",
-				boldYel, KeyForIndex(i), reset,
-				boldWht, s.Name, reset,
-				indicator,
-				dim, dir, reset)
+			rows = withPreviewPane(rows, preview(hovered, max(len(rows), minPreviewLines)), pane)
+		}
+		for _, row := range rows {
+			fmt.Fprintln(w, row)
 		}
-		fmt.Fprintln(tty)
+		fmt.Fprintln(w)
 	} else {
 		if currentSession != "" {
-			fmt.Fprintf(tty, "  %s%s%s %sno sessions%s  %s(in: %s ←)%s\n\n",
+			fmt.Fprintf(w, "  %s%s%s %sno sessions%s  %s(in: %s ←)%s\n\n",
 				boldCyan, b.Name(), reset, dim, reset,
 				dim, currentSession, reset)
 		} else {
-			fmt.Fprintf(tty, "  %s%s%s %sno sessions%s\n\n", boldCyan, b.Name(), reset, dim, reset)
+			fmt.Fprintf(w, "  %s%s%s %sno sessions%s\n\n", boldCyan, b.Name(), reset, dim, reset)
 		}
 	}
 
+	for _, h := range unreachable {
+		fmt.Fprintf(w, "  %s%s unreachable%s\n", dim, h, reset)
+	}
+	if len(unreachable) > 0 {
+		fmt.Fprintln(w)
+	}
+
 	cwd, _ := os.Getwd()
-	defaultName := CounterName(cwd, sessions)
-	fmt.Fprintf(tty, "  %senter%s %snew%s %s%s%s\n", boldGrn, reset, dim, reset, boldWht, defaultName, reset)
-	fmt.Fprintf(tty, "  %sc%s %scustom%s  %sz%s %spick dir%s  %sd%s %s+date%s\n",
+	proj, projErr := project.Find(cwd)
+	if s, ok := v.selected(); ok {
+		fmt.Fprintf(w, "  %senter%s %sattach%s %s%s%s  %sesc%s %sclear%s\n",
+			boldGrn, reset, dim, reset, boldWht, s.Name, reset, yellow, reset, dim, reset)
+	} else if proj != nil {
+		fmt.Fprintf(w, "  %senter%s %sproject%s %s%s%s\n", boldGrn, reset, dim, reset, boldWht, proj.Name, reset)
+	} else {
+		defaultName := CounterName(b, cwd, sessions)
+		fmt.Fprintf(w, "  %senter%s %snew%s %s%s%s\n", boldGrn, reset, dim, reset, boldWht, defaultName, reset)
+		if projErr != nil {
+			fmt.Fprintf(w, "  %s%v%s\n", dim, projErr, reset)
+		}
+	}
+	fmt.Fprintf(w, "  %sc%s %scustom%s  %sz%s %spick dir%s  %sd%s %s+date%s\n",
 		magenta, reset, dim, reset,
 		magenta, reset, dim, reset,
 		cyan, reset, dim, reset)
-	fmt.Fprintf(tty, "  %sk%s %skill%s  %sh%s %shelp%s  %sesc%s %sskip%s\n",
+	fmt.Fprintf(w, "  %sk%s %skill%s  %sh%s %shelp%s  %sesc%s %sskip%s\n",
 		red, reset, dim, reset,
 		cyan, reset, dim, reset,
 		yellow, reset, dim, reset)
-	fmt.Fprintln(tty)
+	if v.pages() > 1 {
+		fmt.Fprintf(w, "  %s>%s %snext page%s  %s<%s %sprev page%s\n",
+			cyan, reset, dim, reset,
+			cyan, reset, dim, reset)
+	}
+	for _, line := range wrapKeys(footerActions(b, sessions), width-2) {
+		fmt.Fprintf(w, "  %s\n", line)
+	}
+	fmt.Fprintln(w)
 
-	fmt.Fprintf(tty, "  %s>%s ", boldCyan, reset)
+	fmt.Fprintf(w, "  %s>%s ", boldCyan, reset)
+}
 
-	oldState, err := term.MakeRaw(int(tty.Fd()))
-	if err != nil {
-		return Action{}, fmt.Errorf("failed to set raw mode: %w", err)
+// highlight renders row in reverse video, re-applying it after every reset.
+func highlight(row string) string {
+	return reverse + strings.ReplaceAll(row, reset, reset+reverse) + reset
+}
+
+// footerActions lists the optional action keys that apply right now.
+func footerActions(b backend.Backend, sessions []backend.Session) []string {
+	var keys []string
+	if len(sessions) > 1 {
+		keys = append(keys, fmt.Sprintf("%s/%s %sfilter%s", cyan, reset, dim, reset))
+		keys = append(keys, fmt.Sprintf("%sS%s %ssort%s", cyan, reset, dim, reset))
+		keys = append(keys, fmt.Sprintf("%sG%s %sgroup%s", cyan, reset, dim, reset))
 	}
-	defer term.Restore(int(tty.Fd()), oldState)
+	if len(sessions) > 0 {
+		keys = append(keys, fmt.Sprintf("%sP%s %spreview%s", cyan, reset, dim, reset))
+		keys = append(keys, fmt.Sprintf("%sM%s %smark%s", red, reset, dim, reset))
+		keys = append(keys, fmt.Sprintf("%sF%s %spin%s", magenta, reset, dim, reset))
+		keys = append(keys, fmt.Sprintf("%sN%s %snote%s", cyan, reset, dim, reset))
+	}
+	if backend.Supports[backend.Renamer](b) {
+		keys = append(keys, fmt.Sprintf("%sR%s %srename%s", magenta, reset, dim, reset))
+	}
+	if backend.Supports[backend.Templater](b) {
+		keys = append(keys, fmt.Sprintf("%sT%s %stemplate%s", magenta, reset, dim, reset))
+	}
+	if inRepo() {
+		keys = append(keys, fmt.Sprintf("%sW%s %sworktree%s", magenta, reset, dim, reset))
+	}
+	return keys
+}
 
-	buf := make([]byte, 3)
-	n, err := tty.Read(buf)
-	term.Restore(int(tty.Fd()), oldState)
-	fmt.Fprintln(tty)
+// cursorPageSize is how far PgUp/PgDn move the cursor.
+const cursorPageSize = 10
 
-	if err != nil {
-		return Action{}, err
+// view is the picker state that survives redraws.
+type view struct {
+	sessions       []backend.Session
+	currentSession string
+	unreachable    []string
+	sortOrder      string
+	grouped        bool
+	groupRoots     []string
+	groups         []string              // group root per session when grouped, else nil
+	page           int                   // shown page
+	perPage        int                   // sessions per page; 0 means MaxSessions
+	cursor         int                   // highlighted row on the page, or -1 until a navigation key
+	marked         map[string]bool       // sessionID of each session marked in mark mode
+	pins           []string              // pinned session names, listed first
+	absent         map[string]bool       // pins with no running session
+	slots          []int                 // key slot per session, or nil when keys follow position
+	branches       map[string]branchInfo // repository state by StartedIn, when git.status is on
+	notes          map[string]state.Note // by sessionID
+}
+
+// setSessions replaces the listed sessions, keeping the page and cursor in
+// range.
+func (v *view) setSessions(sessions []backend.Session, unreachable []string) {
+	v.sessions = sessions
+	v.unreachable = unreachable
+	v.page = min(v.page, v.pages()-1)
+	v.cursor = min(v.cursor, len(v.pageSessions())-1)
+}
+
+// pageSize returns how many sessions a page holds.
+func (v *view) pageSize() int {
+	if v.perPage <= 0 {
+		return MaxSessions
 	}
+	return v.perPage
+}
 
-	input := buf[:n]
-	if len(input) == 1 && input[0] == 'k' {
-		return enterKillMode(tty, sessions)
+// setPageSize changes the page size to fit the terminal, keeping the first
+// session of the shown page (and the highlighted one) on screen.
+func (v *view) setPageSize(n int) {
+	if n == v.pageSize() {
+		return
+	}
+	first := v.page * v.pageSize()
+	highlighted := first + v.cursor
+	v.perPage = n
+	v.page = min(first/n, v.pages()-1)
+	if v.cursor >= 0 {
+		v.page = min(highlighted/n, v.pages()-1)
+		v.cursor = highlighted - v.page*n
 	}
+	v.cursor = min(v.cursor, len(v.pageSessions())-1)
+}
 
-	action := pickerActionForInput(input, sessions)
-	if action.Type == ActionAttach {
-		fmt.Fprintf(tty, "\n  %s>%s %s%s%s\n\n", boldGrn, reset, boldWht, action.Name, reset)
+// pages returns how many pages the sessions fill; always at least one.
+func (v *view) pages() int {
+	return max(1, (len(v.sessions)+v.pageSize()-1)/v.pageSize())
+}
+
+// pageSessions returns the sessions on the shown page. Their keys are
+// pageKeys.
+func (v *view) pageSessions() []backend.Session {
+	start := min(v.page*v.pageSize(), len(v.sessions))
+	return v.sessions[start:min(start+v.pageSize(), len(v.sessions))]
+}
+
+// pageGroups returns the group roots of pageSessions, or nil when ungrouped.
+func (v *view) pageGroups() []string {
+	if v.groups == nil {
+		return nil
 	}
-	return action, nil
+	start := min(v.page*v.pageSize(), len(v.groups))
+	return v.groups[start:min(start+v.pageSize(), len(v.groups))]
+}
+
+// pageKeys returns the key of each session on the shown page: its stable
+// key, or with more sessions than keys, keys restarting at the first one on
+// every page (nil).
+func (v *view) pageKeys() []byte {
+	if v.slots == nil {
+		return nil
+	}
+	start := min(v.page*v.pageSize(), len(v.slots))
+	var keys []byte
+	for _, slot := range v.slots[start:min(start+v.pageSize(), len(v.slots))] {
+		keys = append(keys, KeyForIndex(slot))
+	}
+	return keys
+}
+
+// pageRows returns the keys, groups and pins of pageSessions, without a
+// width limit. Pins come first, so they're on the first page unless there
+// are more pins than fit.
+func (v *view) pageRows() pageRows {
+	n := len(v.pins) - v.page*v.pageSize()
+	return pageRows{
+		keys:   v.pageKeys(),
+		groups: v.pageGroups(),
+		pinned: max(0, min(n, len(v.pageSessions()))),
+		absent: v.absent,
+		marked: v.marked,
+		branch: v.branches,
+		notes:  v.notes,
+	}
+}
+
+// turnPage moves delta pages, wrapping around at either end.
+func (v *view) turnPage(delta int) {
+	if v.pages() == 1 {
+		return
+	}
+	v.page = (v.page + delta + v.pages()) % v.pages()
+	v.cursor = min(v.cursor, len(v.pageSessions())-1)
+}
+
+// markedSessions returns the marked sessions in display order.
+func (v *view) markedSessions() []backend.Session {
+	var marked []backend.Session
+	for _, s := range v.sessions {
+		if v.marked[sessionID(s)] {
+			marked = append(marked, s)
+		}
+	}
+	return marked
+}
+
+// selected returns the highlighted session, if any.
+func (v *view) selected() (backend.Session, bool) {
+	page := v.pageSessions()
+	if v.cursor < 0 || v.cursor >= len(page) {
+		return backend.Session{}, false
+	}
+	return page[v.cursor], true
+}
+
+// sessionRows renders one line per session, with a heading before the
+// pinned sessions, before each remote host's sessions and, when info has
+// groups, before each group of local sessions. The row at cursor and marked
+// rows are highlighted. Rows that would run past info.width columns drop
+// the note, then the detail, then the directory, then shorten the name.
+func sessionRows(sessions []backend.Session, info pageRows, currentSession string, cursor int) []string {
+	groups, width := info.groups, info.width
+	var rows []string
+	host, group := "", ""
+	for i, s := range sessions {
+		if i >= MaxSessions {
+			break
+		}
+		if s.Host != host {
+			host = s.Host
+			rows = append(rows, "", fmt.Sprintf("  %s%s%s %sssh%s", boldCyan, host, reset, dim, reset))
+		} else if i < info.pinned {
+			if i == 0 {
+				rows = append(rows, fmt.Sprintf("  %spinned%s", dim, reset))
+			}
+		} else if groups != nil && s.Host == "" && (i == info.pinned || groups[i] != group) {
+			group = groups[i]
+			if i > 0 {
+				rows = append(rows, "")
+			}
+			rows = append(rows, groupHeading(group))
+		} else if i == info.pinned && i > 0 {
+			rows = append(rows, "")
+		}
+		indicator := fmt.Sprintf("%s.%s", dim, reset)
+		if s.Name == currentSession && s.Host == "" {
+			indicator = fmt.Sprintf("%s←%s", boldCyan, reset)
+		} else if s.Active {
+			indicator = fmt.Sprintf("%s*%s", boldGrn, reset)
+		}
+		name, dir, branch := s.Name, truncatePath(s.StartedIn, 40), ""
+		if s.Host == "" {
+			branch = info.branch[s.StartedIn].label()
+		}
+		if i < info.pinned && info.absent[s.Name] {
+			indicator, dir = fmt.Sprintf("%s-%s", dim, reset), "not running"
+		}
+		tag := ""
+		if s.Backend != "" {
+			tag = fmt.Sprintf(" %s[%s]%s", dim, s.Backend, reset)
+		}
+		key := KeyForIndex(i)
+		if info.keys != nil {
+			key = info.keys[i]
+		}
+		detail := ""
+		if d := sessionDetail(s, time.Now()); d != "" {
+			detail = fmt.Sprintf("  %s%s%s", dim, d, reset)
+		}
+		note := ""
+		if n, ok := info.notes[sessionID(s)]; ok {
+			note = fmt.Sprintf("  %s%s%s", dim, n, reset)
+		}
+		format := func() string {
+			return fmt.Sprintf("%s%c%s  %s%s%s %s%s %s%s%s%s%s%s",
+				boldYel, key, reset,
+				boldWht, name, reset,
+				indicator, tag,
+				dim, dir, reset, branch, detail, note)
+		}
+		row := format()
+		if avail := width - 3; width > 0 && visibleWidth(row) > avail {
+			note = ""
+			if row = format(); visibleWidth(row) > avail {
+				detail = ""
+				row = format()
+			}
+			if visibleWidth(row) > avail {
+				dir, branch = "", ""
+				row = format()
+			}
+			if over := visibleWidth(row) - avail; over > 0 {
+				name = truncateWidth(name, max(utf8.RuneCountInString(name)-over, 4))
+				row = format()
+			}
+		}
+		switch {
+		case i == cursor:
+			row = fmt.Sprintf("%s›%s %s", boldCyan, reset, highlight(row))
+		case info.marked[sessionID(s)]:
+			row = fmt.Sprintf("%s+%s %s", boldRed, reset, highlight(row))
+		default:
+			row = "  " + row
+		}
+		rows = append(rows, row)
+	}
+	return rows
 }
 
-func enterKillMode(tty *os.File, sessions []backend.Session) (Action, error) {
-	if len(sessions) == 0 {
+// enterKillMode asks which session on v's page to kill. redraw re-renders
+// the picker underneath the prompt after a resize, which can change the page.
+func enterKillMode(tty *os.File, v *view, redraw func()) (Action, error) {
+	if running, _ := v.pageRunning(); len(running) == 0 {
 		fmt.Fprintf(tty, "\n  %sno sessions to kill%s\n", dim, reset)
 		time.Sleep(800 * time.Millisecond)
 		return Action{Type: ActionKill}, nil // redraw picker
 	}
 
-	fmt.Fprintf(tty, "\n  %skill%s %swhich session? %sc%s %sclear all%s ", boldRed, reset, dim, boldRed, reset, dim, reset)
-
-	oldState, err := term.MakeRaw(int(tty.Fd()))
-	if err != nil {
-		return Action{}, err
+	prompt := func() {
+		fmt.Fprintf(tty, "\n  %skill%s %swhich session? %sc%s %sclear all%s ", boldRed, reset, dim, boldRed, reset, dim, reset)
 	}
-	defer term.Restore(int(tty.Fd()), oldState)
+	prompt()
 
 	buf := make([]byte, 3)
-	n, _ := tty.Read(buf)
-	term.Restore(int(tty.Fd()), oldState)
+	n, err := readRaw(tty, buf, func() { redraw(); prompt() })
 	fmt.Fprintln(tty)
+	if err != nil {
+		return Action{}, err
+	}
+	sessions, keys := v.pageRunning()
 
 	if n == 1 && buf[0] == 27 {
 		return Action{Type: ActionKill}, nil // cancelled, redraw picker
@@ -270,14 +850,131 @@ func enterKillMode(tty *os.File, sessions []backend.Session) (Action, error) {
 		return Action{Type: ActionKillAll}, nil
 	}
 
-	if idx, ok := IndexForKey(buf[0]); ok && idx < len(sessions) {
-		return Action{Type: ActionKill, Name: sessions[idx].Name}, nil
+	if idx, ok := rowForKey(buf[0], keys, len(sessions)); ok {
+		return Action{Type: ActionKill, Name: sessions[idx].Name, Backend: sessions[idx].Backend, Host: sessions[idx].Host}, nil
 	}
 
 	return Action{Type: ActionKill}, nil // invalid key, redraw picker
 }
 
-func pickerActionForInput(input []byte, sessions []backend.Session) Action {
+func enterRenameMode(tty *os.File, v *view, redraw func()) (Action, error) {
+	if running, _ := v.pageRunning(); len(running) == 0 {
+		fmt.Fprintf(tty, "\n  %sno sessions to rename%s\n", dim, reset)
+		time.Sleep(800 * time.Millisecond)
+		return Action{Type: ActionRename}, nil // redraw picker
+	}
+
+	prompt := func() {
+		fmt.Fprintf(tty, "\n  %srename%s %swhich session?%s ", magenta, reset, dim, reset)
+	}
+	prompt()
+
+	buf := make([]byte, 3)
+	n, _ := readRaw(tty, buf, func() { redraw(); prompt() })
+	fmt.Fprintln(tty)
+	sessions, keys := v.pageRunning()
+
+	if n == 0 || (n == 1 && buf[0] == 27) {
+		return Action{Type: ActionRename}, nil // cancelled, redraw picker
+	}
+
+	if idx, ok := rowForKey(buf[0], keys, len(sessions)); ok {
+		return Action{Type: ActionRename, Name: sessions[idx].Name, Backend: sessions[idx].Backend, Host: sessions[idx].Host}, nil
+	}
+
+	return Action{Type: ActionRename}, nil // invalid key, redraw picker
+}
+
+// enterPinMode asks which session on v's page to pin or unpin.
+func enterPinMode(tty *os.File, v *view, redraw func()) (Action, error) {
+	prompt := func() {
+		fmt.Fprintf(tty, "\n  %spin%s %swhich session?%s ", magenta, reset, dim, reset)
+	}
+	prompt()
+
+	buf := make([]byte, 3)
+	n, err := readRaw(tty, buf, func() { redraw(); prompt() })
+	fmt.Fprintln(tty)
+	if err != nil {
+		return Action{}, err
+	}
+	sessions := v.pageSessions()
+	if n == 1 {
+		if idx, ok := rowForKey(buf[0], v.pageKeys(), len(sessions)); ok {
+			s := sessions[idx]
+			return Action{Type: ActionPin, Name: s.Name, Backend: s.Backend, Host: s.Host}, nil
+		}
+	}
+	return Action{Type: ActionPin}, nil // cancelled or invalid key, redraw picker
+}
+
+func enterTemplateMode(tty *os.File, redraw func()) (Action, error) {
+	names := backend.ListTemplates()
+	if len(names) == 0 {
+		fmt.Fprintf(tty, "\n  %sno templates in %s%s\n", dim, truncatePath(backend.TemplateDir(), 40), reset)
+		time.Sleep(1200 * time.Millisecond)
+		return Action{Type: ActionTemplate}, nil // redraw picker
+	}
+
+	prompt := func() {
+		fmt.Fprintf(tty, "\n  %stemplate%s", magenta, reset)
+		for i, name := range names {
+			if i >= MaxSessions {
+				break
+			}
+			fmt.Fprintf(tty, "  %s%c%s %s", boldYel, KeyForIndex(i), reset, name)
+		}
+		fmt.Fprint(tty, " ")
+	}
+	prompt()
+
+	buf := make([]byte, 3)
+	n, _ := readRaw(tty, buf, func() { redraw(); prompt() })
+	fmt.Fprintln(tty)
+
+	if n == 0 || (n == 1 && buf[0] == 27) {
+		return Action{Type: ActionTemplate}, nil // cancelled, redraw picker
+	}
+	if idx, ok := IndexForKey(buf[0]); ok && idx < len(names) {
+		return Action{Type: ActionTemplate, Name: names[idx]}, nil
+	}
+	return Action{Type: ActionTemplate}, nil // invalid key, redraw picker
+}
+
+// startTemplate creates a new session laid out by the named template.
+func startTemplate(tty *os.File, b backend.Backend, tmplName, name, dir string, inSession bool) (string, error) {
+	tb, ok := backend.Provider[backend.Templater](b)
+	if !ok {
+		return "", fmt.Errorf("%s doesn't support templates", b.Name())
+	}
+	templater, _ := backend.As[backend.Templater](tb)
+	tmpl, err := backend.LoadTemplate(tmplName)
+	if err != nil {
+		return "", err
+	}
+
+	remember(tb, "", "", name, true)
+	fmt.Fprintf(tty, "\n  %s>%s %s%s%s %s%s%s\n\n", boldGrn, reset, boldWht, name, reset, dim, tmplName, reset)
+	if inSession {
+		switcher.Write(switcher.Target{Action: "new", Name: name, Dir: dir, Backend: tb.Name(), Template: tmplName})
+		return b.DetachCommand(), nil
+	}
+	cmd, err := templater.TemplateCommand(name, dir, tmpl)
+	if err != nil {
+		return "", err
+	}
+	return templateExec(name, cmd), nil
+}
+
+// templateExec runs a multi-step template command in a child shell so
+// ZPICK_SESSION applies to all of it, like sessionExec does for attach.
+func templateExec(name, cmd string) string {
+	return fmt.Sprintf("ZPICK_SESSION=%s sh -c %s", backend.ShellQuote(name), backend.ShellQuote(cmd))
+}
+
+// pickerActionForInput maps a keypress to an action. keys holds the key of
+// each of sessions, or nil when keys follow position.
+func pickerActionForInput(input []byte, sessions []backend.Session, keys []byte) Action {
 	if len(input) == 0 {
 		return Action{Type: ActionRetry}
 	}
@@ -301,86 +998,244 @@ func pickerActionForInput(input []byte, sessions []backend.Session) Action {
 		return Action{Type: ActionHelp}
 	}
 
-	idx, ok := IndexForKey(key)
-	if !ok || idx >= len(sessions) {
+	idx, ok := rowForKey(key, keys, len(sessions))
+	if !ok {
 		return Action{Type: ActionRetry}
 	}
 
-	return Action{Type: ActionAttach, Name: sessions[idx].Name}
+	return Action{Type: ActionAttach, Name: sessions[idx].Name, Backend: sessions[idx].Backend, Host: sessions[idx].Host}
 }
 
-func confirmAndKill(tty *os.File, b backend.Backend, name string) error {
-	if os.Getenv("ZPICK_NO_CONFIRM") == "1" {
-		return b.Kill(name)
+// listRemote queries every remote host, returning their sessions and the
+// hosts that couldn't be reached.
+func listRemote(tty *os.File, hosts []string) ([]backend.Session, []string) {
+	fmt.Fprintf(tty, "\033[H\033[2J\n  %squerying %d remote host(s)...%s", dim, len(hosts), reset)
+	var sessions []backend.Session
+	var unreachable []string
+	for _, r := range remote.ListAll(hosts, remote.Timeout) {
+		if r.Err != nil {
+			unreachable = append(unreachable, r.Host)
+			continue
+		}
+		sessions = append(sessions, r.Sessions...)
 	}
+	return sessions, unreachable
+}
 
-	fmt.Fprintf(tty, "  %skill %s%s%s?%s %sy/n%s ", boldRed, boldWht, name, boldRed, reset, dim, reset)
+// killFunc returns the function that kills a session owned by backendName
+// locally, or by zp on host.
+func killFunc(b backend.Backend, backendName, host string) func(string) error {
+	kill := func(name string) error { return remote.Kill(host, name) }
+	if host == "" {
+		rb := backend.Resolve(b, backendName)
+		kill, backendName = rb.Kill, rb.Name()
+	}
+	return func(name string) error {
+		if err := kill(name); err != nil {
+			return err
+		}
+		state.Forget(state.Key(backendName, host, name))
+		return nil
+	}
+}
 
-	oldState, err := term.MakeRaw(int(tty.Fd()))
-	if err != nil {
-		return err
+// remember records that zpick is attaching (or creating) a session, so the
+// recent and created sort orders work on backends without timestamps.
+func remember(b backend.Backend, backendName, host, name string, created bool) {
+	if host == "" {
+		backendName = backend.Resolve(b, backendName).Name()
 	}
-	defer term.Restore(int(tty.Fd()), oldState)
+	state.Touch(state.Key(backendName, host, name), created)
+}
 
-	buf := make([]byte, 1)
-	tty.Read(buf)
-	term.Restore(int(tty.Fd()), oldState)
-	fmt.Fprintln(tty)
+func confirmAndKill(tty *os.File, kill func(string) error, name string, redraw func()) error {
+	if os.Getenv("ZPICK_NO_CONFIRM") == "1" {
+		return kill(name)
+	}
 
-	if buf[0] == 'y' || buf[0] == 'Y' {
-		return b.Kill(name)
+	if confirm(tty, fmt.Sprintf("%skill %s%s%s?%s", boldRed, boldWht, name, boldRed, reset), redraw) {
+		return kill(name)
 	}
 	fmt.Fprintf(tty, "  %scancelled%s\n", dim, reset)
 	return nil
 }
 
-func confirmAndKillAll(tty *os.File, b backend.Backend, sessions []backend.Session) {
-	fmt.Fprintf(tty, "  %skill all %d sessions?%s %sy/n%s ", boldRed, len(sessions), reset, dim, reset)
-
-	oldState, err := term.MakeRaw(int(tty.Fd()))
-	if err != nil {
-		return
-	}
-	defer term.Restore(int(tty.Fd()), oldState)
+// confirm asks question and reports whether it was answered y. A resize
+// calls redraw and asks again below it.
+func confirm(tty *os.File, question string, redraw func()) bool {
+	prompt := func() { fmt.Fprintf(tty, "  %s %sy/n%s ", question, dim, reset) }
+	prompt()
 
 	buf := make([]byte, 1)
-	tty.Read(buf)
-	term.Restore(int(tty.Fd()), oldState)
+	n, err := readRaw(tty, buf, func() { redraw(); fmt.Fprintln(tty); prompt() })
 	fmt.Fprintln(tty)
+	return err == nil && n == 1 && (buf[0] == 'y' || buf[0] == 'Y')
+}
+
+// confirmAndKillAll kills every local session. Sessions on remote hosts are
+// left alone; mark mode can kill those.
+func confirmAndKillAll(tty *os.File, b backend.Backend, sessions []backend.Session, redraw func()) {
+	var local []backend.Session
+	for _, s := range sessions {
+		if s.Host == "" {
+			local = append(local, s)
+		}
+	}
+	question := fmt.Sprintf("%skill all %d sessions?%s", boldRed, len(local), reset)
+	if skipped := len(sessions) - len(local); skipped > 0 {
+		question = fmt.Sprintf("%skill all %d local sessions?%s %s(%d remote kept)%s", boldRed, len(local), reset, dim, skipped, reset)
+	}
+	confirmAndApply(tty, question, local, fmt.Sprintf("%skilled%s", boldRed, reset), func(s backend.Session) error {
+		return killFunc(b, s.Backend, s.Host)(s.Name)
+	}, redraw)
+}
 
-	if buf[0] != 'y' && buf[0] != 'Y' {
+// confirmAndApply asks question once, then applies fn to every session and
+// reports each one: done (e.g. "killed") or the error.
+func confirmAndApply(tty *os.File, question string, sessions []backend.Session, done string, fn func(backend.Session) error, redraw func()) {
+	if os.Getenv("ZPICK_NO_CONFIRM") != "1" && !confirm(tty, question, redraw) {
 		fmt.Fprintf(tty, "  %scancelled%s\n", dim, reset)
 		return
 	}
 
 	for _, s := range sessions {
-		if err := b.Kill(s.Name); err != nil {
+		if err := fn(s); err != nil {
 			fmt.Fprintf(tty, "  %sfailed: %s — %v%s\n", dim, s.Name, err, reset)
 		} else {
-			fmt.Fprintf(tty, "  %skilled%s %s%s%s\n", boldRed, reset, boldWht, s.Name, reset)
+			fmt.Fprintf(tty, "  %s %s%s%s\n", done, boldWht, s.Name, reset)
+		}
+	}
+}
+
+// promptAndRename asks for a new name and renames the session if the owning
+// backend supports it.
+func promptAndRename(tty *os.File, b backend.Backend, name string, sessions []backend.Session, redraw func()) {
+	r, ok := backend.As[backend.Renamer](b)
+	if !ok {
+		fmt.Fprintf(tty, "  %s%s can't rename sessions%s\n", dim, b.Name(), reset)
+		time.Sleep(800 * time.Millisecond)
+		return
+	}
+
+	prompt := func() {
+		fmt.Fprintf(tty, "  %srename%s %s%s%s %sto:%s ", magenta, reset, boldWht, name, reset, dim, reset)
+	}
+	prompt()
+	newName, ok := readLineRaw(tty, func() { redraw(); fmt.Fprintln(tty); prompt() })
+	if !ok || newName == "" || newName == name {
+		fmt.Fprintf(tty, "  %scancelled%s\n", dim, reset)
+		return
+	}
+	for _, s := range sessions {
+		if s.Name == newName {
+			fmt.Fprintf(tty, "  %sfailed: %s already exists%s\n", dim, newName, reset)
+			time.Sleep(800 * time.Millisecond)
+			return
 		}
 	}
+
+	if err := r.Rename(name, newName); err != nil {
+		fmt.Fprintf(tty, "  %sfailed: %v%s\n", dim, err, reset)
+		time.Sleep(800 * time.Millisecond)
+		return
+	}
+	state.Rename(state.Key(b.Name(), "", name), state.Key(b.Name(), "", newName))
+	fmt.Fprintf(tty, "  %srenamed%s %s%s%s → %s%s%s\n", magenta, reset, dim, name, reset, boldWht, newName, reset)
 }
 
-func handleCustom(tty *os.File, b backend.Backend, sessions []backend.Session, inSession bool) (string, error) {
-	fmt.Fprintf(tty, "\n  %sname:%s ", magenta, reset)
+func handleCustom(tty *os.File, b backend.Backend, sessions []backend.Session, inSession bool, redraw func()) (string, error) {
+	prompt := func() { fmt.Fprintf(tty, "\n  %sname:%s ", magenta, reset) }
+	prompt()
 
-	customName, ok := readLineRaw(tty)
+	customName, ok := readLineRaw(tty, func() { redraw(); prompt() })
 	if !ok || customName == "" {
 		return "", nil
 	}
 
+	remember(b, "", "", customName, true)
 	fmt.Fprintf(tty, "\n  %s>%s %s%s%s\n\n", boldGrn, reset, boldWht, customName, reset)
 	if inSession {
 		switcher.Write(switcher.Target{Action: "new", Name: customName})
 		return b.DetachCommand(), nil
 	}
-	return sessionExec(b, customName, ""), nil
+	return sessionExec(backend.Resolve(b, ""), customName, ""), nil
+}
+
+// confirmProject shows the session an unapproved .zpick file sets up and
+// what it would run, and asks whether to allow it. An allowed file is
+// remembered until it changes.
+func confirmProject(tty *os.File, p *project.Project, redraw func()) bool {
+	fmt.Fprintln(tty)
+	showProject(tty, p)
+	if !confirm(tty, fmt.Sprintf("%sallow this .zpick file?%s", boldYel, reset), func() { redraw(); showProject(tty, p) }) {
+		fmt.Fprintf(tty, "  %scancelled%s\n", dim, reset)
+		return false
+	}
+	if err := p.Trust(); err != nil {
+		fmt.Fprintf(tty, "  %snot remembered: %v%s\n", dim, err, reset)
+	}
+	return true
+}
+
+// showProject lists what p sets and runs.
+func showProject(tty *os.File, p *project.Project) {
+	fmt.Fprintf(tty, "  %s%s%s %swill run:%s\n", boldWht, p.Path, reset, dim, reset)
+	fmt.Fprintf(tty, "    %ssession%s %s\n", dim, reset, p.Name)
+	if p.Backend != "" {
+		fmt.Fprintf(tty, "    %sbackend%s %s\n", dim, reset, p.Backend)
+	}
+	fmt.Fprintf(tty, "    %sdir%s     %s\n", dim, reset, p.Dir)
+	if p.Command != "" {
+		fmt.Fprintf(tty, "    %scommand%s %s\n", dim, reset, p.Command)
+	}
+	for _, k := range slices.Sorted(maps.Keys(p.Env)) {
+		fmt.Fprintf(tty, "    %senv%s     %s=%s\n", dim, reset, k, p.Env[k])
+	}
+}
+
+// startProject creates or attaches the session described by a .zpick file.
+// The startup command only runs when the session doesn't exist yet.
+func startProject(tty *os.File, b backend.Backend, p *project.Project, inSession bool) string {
+	pb := backend.Named(b, p.Backend)
+	creating := true
+	if existing, err := pb.FastList(); err == nil {
+		for _, s := range existing {
+			if s.Name == p.Name {
+				creating = false
+				break
+			}
+		}
+	}
+
+	remember(pb, "", "", p.Name, creating)
+	fmt.Fprintf(tty, "\n  %s>%s %s%s%s %sproject%s\n\n", boldGrn, reset, boldWht, p.Name, reset, dim, reset)
+	if inSession {
+		target := switcher.Target{Action: "attach", Name: p.Name, Backend: pb.Name(), Env: p.Env}
+		if creating {
+			target.Action = "new"
+			target.Dir = p.Dir
+			target.Command = p.Command
+		}
+		switcher.Write(target)
+		return b.DetachCommand()
+	}
+
+	cmd := project.ShellPrefix(p.Env, p.Command, creating) + sessionExec(pb, p.Name, "")
+	if creating {
+		return fmt.Sprintf("cd %s && %s", backend.ShellQuote(p.Dir), cmd)
+	}
+	return cmd
 }
 
 // readLineRaw reads a line in raw mode, supporting escape to cancel and backspace.
 // Returns the entered string and true, or empty string and false if cancelled.
-func readLineRaw(tty *os.File) (string, bool) {
+// On a resize it calls redraw, which should end with the prompt, and then
+// shows what was typed so far again.
+func readLineRaw(tty *os.File, redraw func()) (string, bool) {
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	defer signal.Stop(winch)
+
 	oldState, err := term.MakeRaw(int(tty.Fd()))
 	if err != nil {
 		return "", false
@@ -390,7 +1245,10 @@ func readLineRaw(tty *os.File) (string, bool) {
 	var buf []byte
 	b := make([]byte, 3)
 	for {
-		n, err := tty.Read(b)
+		n, err := readKey(tty, b, oldState, winch, func() {
+			redraw()
+			fmt.Fprint(tty, string(buf))
+		})
 		if err != nil || n == 0 {
 			return "", false
 		}
@@ -426,7 +1284,7 @@ func readLineRaw(tty *os.File) (string, bool) {
 // their session env var in the child shell, so ZPICK_SESSION acts as a universal
 // "already inside a session" marker.
 func sessionExec(b backend.Backend, name, prefix string) string {
-	cmd := fmt.Sprintf("ZPICK_SESSION=%q %s", name, b.AttachCommand(name, ""))
+	cmd := fmt.Sprintf("ZPICK_SESSION=%s %s", backend.ShellQuote(name), b.AttachCommand(name, ""))
 	if prefix != "" {
 		return prefix + " && " + cmd
 	}
@@ -449,6 +1307,42 @@ func runZoxide(tty *os.File) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// sessionDetail summarises a session's metadata for the picker row:
+// foreground command, window count, and time since last activity (or the
+// exit status for sessions whose task has ended).
+func sessionDetail(s backend.Session, now time.Time) string {
+	var parts []string
+	if s.Command != "" {
+		parts = append(parts, s.Command)
+	}
+	if s.Windows > 1 {
+		parts = append(parts, fmt.Sprintf("%dw", s.Windows))
+	}
+	switch {
+	case !s.EndedAt.IsZero():
+		parts = append(parts, fmt.Sprintf("exited %d", s.ExitCode))
+	case !s.LastActivity.IsZero():
+		parts = append(parts, formatAge(now.Sub(s.LastActivity)))
+	case !s.CreatedAt.IsZero():
+		parts = append(parts, formatAge(now.Sub(s.CreatedAt)))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatAge renders a duration as a compact age like "now", "5m", "3h", "2d".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
 func truncatePath(path string, maxLen int) string {
 	home := os.Getenv("HOME")
 	if home != "" {
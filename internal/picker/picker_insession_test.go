@@ -2,10 +2,13 @@ package picker
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 
 	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/backend/fake"
+	"github.com/nerveband/zpick/internal/project"
 	"github.com/nerveband/zpick/internal/switcher"
 )
 
@@ -24,6 +27,7 @@ func (m *mockBackend) Name() string                        { return m.name }
 func (m *mockBackend) BinaryName() string                  { return m.binaryName }
 func (m *mockBackend) SessionEnvVar() string               { return m.sessionEnvVar }
 func (m *mockBackend) InSession() bool                     { return m.inSession }
+func (m *mockBackend) CurrentSessionName() string           { return "" }
 func (m *mockBackend) Available() (bool, error)            { return m.available, nil }
 func (m *mockBackend) Version() (string, error)            { return "1.0.0", nil }
 func (m *mockBackend) List() ([]backend.Session, error)    { return m.sessions, nil }
@@ -228,5 +232,80 @@ func TestShowPickerAcceptsCurrentSession(t *testing.T) {
 	// This test just verifies the function signature compiles.
 	// The actual showPicker function reads from /dev/tty so we can't
 	// fully test it in CI, but we verify it has the right signature.
-	var _ func(*os.File, backend.Backend, []backend.Session, string) (Action, error) = showPicker
+	var _ func(*os.File, backend.Backend, *view) (Action, error) = showPicker
+	_ = view{currentSession: "alpha"}
+}
+
+func TestStartProjectInSessionWritesTarget(t *testing.T) {
+	tmpDir := t.TempDir()
+	switcher.SetPath(filepath.Join(tmpDir, "switch-target"))
+	defer switcher.SetPath("")
+
+	tty, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tty.Close()
+
+	b := &mockBackend{name: "tmux", binaryName: "tmux", detachCmd: "tmux detach-client"}
+	p := &project.Project{Name: "api", Dir: "/src/api", Command: "make dev", Env: map[string]string{"PORT": "8080"}}
+
+	if cmd := startProject(tty, b, p, true); cmd != "tmux detach-client" {
+		t.Errorf("startProject() = %q, want detach command", cmd)
+	}
+	target, err := switcher.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target.Action != "new" || target.Name != "api" || target.Dir != "/src/api" ||
+		target.Command != "make dev" || target.Env["PORT"] != "8080" || target.Backend != "tmux" {
+		t.Errorf("target = %+v", target)
+	}
+}
+
+func TestStartProjectAttachesExistingSession(t *testing.T) {
+	tty, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tty.Close()
+
+	b := &mockBackend{name: "tmux", binaryName: "tmux", sessions: []backend.Session{{Name: "api"}}}
+	p := &project.Project{Name: "api", Dir: "/src/api", Command: "make dev"}
+
+	cmd := startProject(tty, b, p, false)
+	if want := `ZPICK_SESSION='api' tmux attach api`; cmd != want {
+		t.Errorf("startProject() = %q, want %q", cmd, want)
+	}
+}
+
+func TestStartProjectQuotesNameAndDir(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	tty, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tty.Close()
+
+	// Neither the name nor the dir may run anything when the shell hook
+	// evaluates the command.
+	root := t.TempDir()
+	dir := filepath.Join(root, "$(touch pwned-dir)")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	b := fake.NewAt(filepath.Join(root, "fake.json"))
+	p := &project.Project{Name: "x$(touch pwned-name)`touch pwned-tick`", Dir: dir}
+
+	cmd := startProject(tty, b, p, false)
+	sh := exec.Command("sh", "-c", cmd)
+	sh.Dir = root
+	if out, err := sh.CombinedOutput(); err != nil {
+		t.Fatalf("sh -c %q: %v\n%s", cmd, err, out)
+	}
+	for _, d := range []string{root, dir} {
+		if matches, _ := filepath.Glob(filepath.Join(d, "pwned-*")); len(matches) > 0 {
+			t.Errorf("startProject() = %q ran %v", cmd, matches)
+		}
+	}
 }
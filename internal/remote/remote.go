@@ -0,0 +1,105 @@
+// Package remote lists and attaches sessions on other machines by running zp
+// there over ssh. `zp list --json` is the wire format.
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nerveband/zpick/internal/backend"
+)
+
+// Timeout bounds how long the picker waits for every host to answer.
+const Timeout = 5 * time.Second
+
+// Result is one host's answer to ListAll.
+type Result struct {
+	Host     string
+	Sessions []backend.Session
+	Err      error
+}
+
+// sshArgs are passed to every non-interactive ssh call so an unreachable or
+// password-prompting host fails fast instead of hanging the picker.
+var sshArgs = []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=3"}
+
+// List runs `zp list --json` on host and returns its sessions tagged with
+// the host.
+func List(ctx context.Context, host string) ([]backend.Session, error) {
+	args := append(append([]string{}, sshArgs...), host, "zp list --json")
+	out, err := exec.CommandContext(ctx, "ssh", args...).Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return nil, fmt.Errorf("%s: %s", host, strings.TrimSpace(string(ee.Stderr)))
+		}
+		return nil, fmt.Errorf("%s: %w", host, err)
+	}
+	return parseList(out, host)
+}
+
+// ListAll queries every host concurrently and returns results in host order.
+func ListAll(hosts []string, timeout time.Duration) []Result {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	results := make([]Result, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sessions, err := List(ctx, host)
+			results[i] = Result{Host: host, Sessions: sessions, Err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// AttachCommand returns the shell command that attaches (or creates) name on
+// host through an interactive ssh session.
+func AttachCommand(host, name string) string {
+	return fmt.Sprintf("ssh -t %s zp attach %s", quote(host), quote(quote(name)))
+}
+
+// Kill kills name on host.
+func Kill(host, name string) error {
+	args := append(append([]string{}, sshArgs...), host, "zp kill "+quote(name))
+	if out, err := exec.Command("ssh", args...).CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %s", host, msg)
+		}
+		return fmt.Errorf("%s: %w", host, err)
+	}
+	return nil
+}
+
+// listResult is the subset of `zp list --json` output used here.
+type listResult struct {
+	Sessions []backend.Session `json:"sessions"`
+}
+
+func parseList(data []byte, host string) ([]backend.Session, error) {
+	var r listResult
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%s: invalid zp list output: %w", host, err)
+	}
+	for i := range r.Sessions {
+		r.Sessions[i].Host = host
+	}
+	return r.Sessions, nil
+}
+
+// quote shell-quotes s unless it only contains characters that are safe
+// unquoted, which keeps the common case readable.
+func quote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_.@:/+=,") == "" {
+		return s
+	}
+	return backend.ShellQuote(s)
+}
@@ -0,0 +1,63 @@
+package remote
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseList(t *testing.T) {
+	data := []byte(`{"sessions": [{"name": "api", "clients": 1, "started_in": "/srv/api", "active": true}], "count": 1}`)
+	sessions, err := parseList(data, "devbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Name != "api" || sessions[0].Host != "devbox" || !sessions[0].Active {
+		t.Errorf("parseList() = %+v", sessions)
+	}
+	if _, err := parseList([]byte("zp: command not found"), "devbox"); err == nil {
+		t.Error("parseList should reject non-JSON output")
+	}
+}
+
+func TestAttachCommand(t *testing.T) {
+	tests := []struct {
+		host, name, want string
+	}{
+		{"devbox", "api", "ssh -t devbox zp attach api"},
+		{"me@vm1", "web-2", "ssh -t me@vm1 zp attach web-2"},
+		{"devbox", "my app", `ssh -t devbox zp attach ''"'"'my app'"'"''`},
+	}
+	for _, tt := range tests {
+		if got := AttachCommand(tt.host, tt.name); got != tt.want {
+			t.Errorf("AttachCommand(%q, %q) = %q, want %q", tt.host, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestListAllUsesSSH(t *testing.T) {
+	bin := t.TempDir()
+	script := `#!/bin/sh
+# last two args are the host and the remote command
+for a; do host=$prev; prev=$a; done
+case "$host" in
+devbox) echo '{"sessions": [{"name": "api", "clients": 0, "started_in": "", "active": false}]}' ;;
+*) echo "ssh: connect to host $host: Connection refused" >&2; exit 255 ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(bin, "ssh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	results := ListAll([]string{"devbox", "offline"}, Timeout)
+	if len(results) != 2 {
+		t.Fatalf("ListAll() returned %d results", len(results))
+	}
+	if results[0].Err != nil || len(results[0].Sessions) != 1 || results[0].Sessions[0].Host != "devbox" {
+		t.Errorf("devbox result = %+v", results[0])
+	}
+	if results[1].Host != "offline" || results[1].Err == nil {
+		t.Errorf("offline result = %+v, want an error", results[1])
+	}
+}
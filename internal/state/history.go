@@ -0,0 +1,92 @@
+package state
+
+import "time"
+
+// historyFile holds when zpick created and last attached each session.
+const historyFile = "history.json"
+
+// Record is what zpick remembers about one session. Backends that report
+// their own timestamps take precedence; these fill the gaps.
+type Record struct {
+	Created  time.Time `json:"created,omitzero"`
+	Attached time.Time `json:"attached,omitzero"`
+}
+
+// History maps session keys (see Key) to their records.
+type History map[string]Record
+
+// LoadHistory reads the history file. A missing or unreadable file gives an
+// empty history; sorting by it is best-effort.
+func LoadHistory() History {
+	h := History{}
+	if err := load(historyFile, &h); err != nil || h == nil {
+		return History{}
+	}
+	return h
+}
+
+// Touch records that the session was just attached. created marks a session
+// zpick is creating; it only sets the creation time if none is recorded.
+func Touch(key string, created bool) error {
+	h := LoadHistory()
+	r := h[key]
+	now := time.Now()
+	r.Attached = now
+	if created && r.Created.IsZero() {
+		r.Created = now
+	}
+	h[key] = r
+	return save(historyFile, h)
+}
+
+// Rename moves a session's record, picker key and note to its new key.
+func Rename(oldKey, newKey string) error {
+	h := LoadHistory()
+	if r, ok := h[oldKey]; ok {
+		delete(h, oldKey)
+		h[newKey] = r
+		if err := save(historyFile, h); err != nil {
+			return err
+		}
+	}
+	s := LoadSlots()
+	if slot, ok := s[oldKey]; ok {
+		delete(s, oldKey)
+		s[newKey] = slot
+		if err := SaveSlots(s); err != nil {
+			return err
+		}
+	}
+	n := LoadNotes()
+	if note, ok := n[oldKey]; ok {
+		delete(n, oldKey)
+		n[newKey] = note
+		return save(notesFile, n)
+	}
+	return nil
+}
+
+// Forget drops a killed session's record, picker key and note, so a new
+// session reusing the name starts fresh.
+func Forget(key string) error {
+	h := LoadHistory()
+	if _, ok := h[key]; ok {
+		delete(h, key)
+		if err := save(historyFile, h); err != nil {
+			return err
+		}
+	}
+	s := LoadSlots()
+	if _, ok := s[key]; ok {
+		delete(s, key)
+		if err := SaveSlots(s); err != nil {
+			return err
+		}
+	}
+	n := LoadNotes()
+	if _, ok := n[key]; ok {
+		delete(n, key)
+		return save(notesFile, n)
+	}
+	return nil
+}
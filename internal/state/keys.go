@@ -0,0 +1,23 @@
+package state
+
+// keysFile holds the picker key of each session.
+const keysFile = "keys.json"
+
+// Slots maps session keys (see Key) to the position of their picker key in
+// the key sequence, so a session keeps its key while others come and go.
+type Slots map[string]int
+
+// LoadSlots reads the key file. A missing or unreadable file gives no
+// slots; every session is then assigned a fresh one.
+func LoadSlots() Slots {
+	s := Slots{}
+	if err := load(keysFile, &s); err != nil || s == nil {
+		return Slots{}
+	}
+	return s
+}
+
+// SaveSlots replaces the key file with s.
+func SaveSlots(s Slots) error {
+	return save(keysFile, s)
+}
@@ -0,0 +1,95 @@
+package state
+
+import (
+	"slices"
+	"strings"
+)
+
+// notesFile holds the notes users attach to sessions.
+const notesFile = "notes.json"
+
+// Note is free text and tags attached to a session.
+type Note struct {
+	Text string   `json:"text,omitempty"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+// ParseNote splits a note as typed: words starting with # are tags, the
+// rest is the text. "waiting on CI #prod" has text "waiting on CI" and tag
+// "prod".
+func ParseNote(s string) Note {
+	var n Note
+	var words []string
+	for _, w := range strings.Fields(s) {
+		if tag, ok := strings.CutPrefix(w, "#"); ok && tag != "" {
+			if !slices.Contains(n.Tags, tag) {
+				n.Tags = append(n.Tags, tag)
+			}
+			continue
+		}
+		words = append(words, w)
+	}
+	n.Text = strings.Join(words, " ")
+	return n
+}
+
+// String formats n the way ParseNote reads it.
+func (n Note) String() string {
+	parts := []string{}
+	if n.Text != "" {
+		parts = append(parts, n.Text)
+	}
+	for _, tag := range n.Tags {
+		parts = append(parts, "#"+tag)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Empty reports whether n has neither text nor tags.
+func (n Note) Empty() bool {
+	return n.Text == "" && len(n.Tags) == 0
+}
+
+// Notes maps session keys (see Key) to their notes.
+type Notes map[string]Note
+
+// LoadNotes reads the notes file. A missing or unreadable file gives no
+// notes.
+func LoadNotes() Notes {
+	n := Notes{}
+	if err := load(notesFile, &n); err != nil || n == nil {
+		return Notes{}
+	}
+	return n
+}
+
+// SetNote saves the note for a session; an empty note removes it.
+func SetNote(key string, n Note) error {
+	notes := LoadNotes()
+	if n.Empty() {
+		if _, ok := notes[key]; !ok {
+			return nil
+		}
+		delete(notes, key)
+	} else {
+		notes[key] = n
+	}
+	return save(notesFile, notes)
+}
+
+// PruneNotes drops the notes of sessions for which gone returns true, and
+// returns the notes that are left.
+func PruneNotes(gone func(key string) bool) Notes {
+	notes := LoadNotes()
+	pruned := false
+	for key := range notes {
+		if gone(key) {
+			delete(notes, key)
+			pruned = true
+		}
+	}
+	if pruned {
+		save(notesFile, notes)
+	}
+	return notes
+}
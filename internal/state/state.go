@@ -0,0 +1,67 @@
+// Package state keeps what zpick remembers between runs, as JSON files in
+// the state directory. Unlike config.toml these are written by zpick itself
+// and never need editing by hand.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Dir returns the zpick state directory, respecting XDG_STATE_HOME.
+func Dir() string {
+	if d := os.Getenv("XDG_STATE_HOME"); d != "" {
+		return filepath.Join(d, "zpick")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".local", "state", "zpick")
+}
+
+// Key identifies a session across runs: its backend and name, or its host
+// and name for sessions on a remote host.
+func Key(backendName, host, name string) string {
+	if host != "" {
+		return host + ":" + name
+	}
+	return backendName + "/" + name
+}
+
+// load decodes the named state file into v. A missing file leaves v as is.
+func load(file string, v any) error {
+	data, err := os.ReadFile(filepath.Join(Dir(), file))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// save writes v to the named state file, replacing it atomically so a
+// concurrent zp never reads half a file.
+func save(file string, v any) error {
+	if err := os.MkdirAll(Dir(), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(Dir(), file+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(Dir(), file))
+}
@@ -0,0 +1,123 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDirRespectsXDGStateHome(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/xdg-state")
+	if got := Dir(); got != "/tmp/xdg-state/zpick" {
+		t.Errorf("Dir() = %q", got)
+	}
+}
+
+func TestKey(t *testing.T) {
+	if got := Key("tmux", "", "api"); got != "tmux/api" {
+		t.Errorf("local key = %q", got)
+	}
+	if got := Key("tmux", "devbox", "api"); got != "devbox:api" {
+		t.Errorf("remote key = %q", got)
+	}
+}
+
+func TestHistoryTouchRenameForget(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	if h := LoadHistory(); len(h) != 0 {
+		t.Fatalf("missing file should give an empty history, got %v", h)
+	}
+
+	if err := Touch("tmux/api", true); err != nil {
+		t.Fatal(err)
+	}
+	first := LoadHistory()["tmux/api"]
+	if first.Created.IsZero() || first.Attached.IsZero() {
+		t.Fatalf("new session record = %+v", first)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if err := Touch("tmux/api", true); err != nil {
+		t.Fatal(err)
+	}
+	second := LoadHistory()["tmux/api"]
+	if !second.Created.Equal(first.Created) {
+		t.Errorf("created changed on reattach: %v -> %v", first.Created, second.Created)
+	}
+	if !second.Attached.After(first.Attached) {
+		t.Errorf("attached not updated: %v -> %v", first.Attached, second.Attached)
+	}
+
+	if err := Rename("tmux/api", "tmux/web"); err != nil {
+		t.Fatal(err)
+	}
+	h := LoadHistory()
+	if _, ok := h["tmux/api"]; ok || !h["tmux/web"].Created.Equal(first.Created) {
+		t.Errorf("after rename: %v", h)
+	}
+
+	if err := Forget("tmux/web"); err != nil {
+		t.Fatal(err)
+	}
+	if h := LoadHistory(); len(h) != 0 {
+		t.Errorf("after forget: %v", h)
+	}
+}
+
+func TestSlotsFollowRenameAndForget(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	if err := SaveSlots(Slots{"tmux/api": 2, "tmux/db": 0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Rename("tmux/api", "tmux/web"); err != nil {
+		t.Fatal(err)
+	}
+	if s := LoadSlots(); s["tmux/web"] != 2 || len(s) != 2 {
+		t.Errorf("after rename: %v", s)
+	}
+	if err := Forget("tmux/db"); err != nil {
+		t.Fatal(err)
+	}
+	if s := LoadSlots(); len(s) != 1 {
+		t.Errorf("after forget: %v", s)
+	}
+}
+
+func TestParseNote(t *testing.T) {
+	n := ParseNote("  waiting on  CI #prod #ci #prod")
+	if n.Text != "waiting on CI" || len(n.Tags) != 2 || n.Tags[0] != "prod" || n.Tags[1] != "ci" {
+		t.Errorf("ParseNote() = %+v", n)
+	}
+	if got := n.String(); got != "waiting on CI #prod #ci" {
+		t.Errorf("String() = %q", got)
+	}
+	if !ParseNote("   ").Empty() {
+		t.Error("blank input should give an empty note")
+	}
+}
+
+func TestNotes(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	SetNote("tmux/api", ParseNote("prod hotfix #prod"))
+	SetNote("tmux/old", ParseNote("stale"))
+	SetNote("devbox:logs", ParseNote("remote"))
+	if err := Rename("tmux/api", "tmux/web"); err != nil {
+		t.Fatal(err)
+	}
+
+	left := PruneNotes(func(key string) bool { return key == "tmux/old" })
+	if len(left) != 2 || left["tmux/web"].Text != "prod hotfix" {
+		t.Errorf("after prune: %v", left)
+	}
+	if err := SetNote("tmux/web", Note{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Forget("devbox:logs"); err != nil {
+		t.Fatal(err)
+	}
+	if n := LoadNotes(); len(n) != 0 {
+		t.Errorf("after clearing and forgetting: %v", n)
+	}
+}
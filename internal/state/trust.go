@@ -0,0 +1,26 @@
+package state
+
+// trustFile holds the .zpick files allowed to run commands.
+const trustFile = "trusted.json"
+
+// Trusted maps the paths of .zpick files to the SHA-256 of the contents
+// that were approved. Editing a file makes it untrusted again.
+type Trusted map[string]string
+
+// LoadTrusted reads the trust file. A missing or unreadable file trusts
+// nothing.
+func LoadTrusted() Trusted {
+	t := Trusted{}
+	if err := load(trustFile, &t); err != nil || t == nil {
+		return Trusted{}
+	}
+	return t
+}
+
+// Trust records that the .zpick file at path may run while its contents
+// hash to hash.
+func Trust(path, hash string) error {
+	t := LoadTrusted()
+	t[path] = hash
+	return save(trustFile, t)
+}
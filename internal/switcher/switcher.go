@@ -13,6 +13,21 @@ type Target struct {
 	Action string `json:"action"` // "attach" or "new"
 	Name   string `json:"name"`
 	Dir    string `json:"dir,omitempty"`
+
+	// Backend names the owning backend when switching from the aggregate view
+	// or to a project session on a different backend.
+	Backend string `json:"backend,omitempty"`
+
+	// Env and Command come from a .zpick project file. Command only runs when
+	// the session is created ("new").
+	Env     map[string]string `json:"env,omitempty"`
+	Command string            `json:"command,omitempty"`
+
+	// Host is set when the target session lives on a remote host.
+	Host string `json:"host,omitempty"`
+
+	// Template names a layout template to build a "new" session from.
+	Template string `json:"template,omitempty"`
 }
 
 // filePath is the switch-target file location.
@@ -0,0 +1,167 @@
+// Package theme holds the ANSI styles the picker, help and guard screens
+// draw with, so they can be swapped for light terminals, high contrast or
+// no color at all.
+package theme
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nerveband/zpick/internal/config"
+)
+
+// Palette is a set of styles as ANSI escape sequences. The slots are named
+// after the colors of the default theme; other themes map them to whatever
+// reads best, and an empty slot draws plain text.
+type Palette struct {
+	Reset      string
+	Dim        string
+	Red        string
+	Cyan       string
+	Green      string
+	Yellow     string
+	Magenta    string
+	BoldRed    string
+	BoldCyan   string
+	BoldGreen  string
+	BoldYellow string
+	BoldWhite  string
+	Reverse    string
+}
+
+func sgr(params string) string {
+	if params == "" {
+		return ""
+	}
+	return "\033[" + params + "m"
+}
+
+// Default is the original dark-background palette.
+var Default = Palette{
+	Reset:      sgr("0"),
+	Dim:        sgr("2"),
+	Red:        sgr("31"),
+	Cyan:       sgr("36"),
+	Green:      sgr("32"),
+	Yellow:     sgr("33"),
+	Magenta:    sgr("35"),
+	BoldRed:    sgr("1;31"),
+	BoldCyan:   sgr("1;36"),
+	BoldGreen:  sgr("1;32"),
+	BoldYellow: sgr("1;33"),
+	BoldWhite:  sgr("1;97"),
+	Reverse:    sgr("7"),
+}
+
+// builtin returns the named built-in palette.
+func builtin(name string) (Palette, bool) {
+	switch name {
+	case "default":
+		return Default, true
+	case "light":
+		// Bright white and yellow vanish on a light background.
+		p := Default
+		p.BoldWhite = sgr("1")
+		p.BoldYellow = sgr("1;34")
+		p.Yellow = sgr("34")
+		return p, true
+	case "high-contrast":
+		return Palette{
+			Reset:      sgr("0"),
+			Red:        sgr("1;91"),
+			Cyan:       sgr("1;96"),
+			Green:      sgr("1;92"),
+			Yellow:     sgr("1;93"),
+			Magenta:    sgr("1;95"),
+			BoldRed:    sgr("1;91"),
+			BoldCyan:   sgr("1;96"),
+			BoldGreen:  sgr("1;92"),
+			BoldYellow: sgr("1;93"),
+			BoldWhite:  sgr("1"),
+			Reverse:    sgr("7"),
+		}, true
+	case "mono":
+		// No color, only the attributes that carry meaning.
+		return Palette{
+			Reset:      sgr("0"),
+			Dim:        sgr("2"),
+			BoldRed:    sgr("1"),
+			BoldCyan:   sgr("1"),
+			BoldGreen:  sgr("1"),
+			BoldYellow: sgr("1"),
+			BoldWhite:  sgr("1"),
+			Reverse:    sgr("7"),
+		}, true
+	}
+	return Palette{}, false
+}
+
+// slots maps the names used in theme.palette overrides to palette fields.
+func (p *Palette) slots() map[string]*string {
+	return map[string]*string{
+		"dim":         &p.Dim,
+		"red":         &p.Red,
+		"cyan":        &p.Cyan,
+		"green":       &p.Green,
+		"yellow":      &p.Yellow,
+		"magenta":     &p.Magenta,
+		"bold_red":    &p.BoldRed,
+		"bold_cyan":   &p.BoldCyan,
+		"bold_green":  &p.BoldGreen,
+		"bold_yellow": &p.BoldYellow,
+		"bold_white":  &p.BoldWhite,
+		"reverse":     &p.Reverse,
+	}
+}
+
+// Override applies "slot=params" entries, where params are SGR parameters
+// such as "1;34" (bold blue) or "" (plain). Entries that don't parse are
+// skipped and reported together.
+func (p *Palette) Override(entries []string) error {
+	slots := p.slots()
+	var bad []string
+	for _, entry := range entries {
+		name, params, ok := strings.Cut(entry, "=")
+		name, params = strings.TrimSpace(name), strings.TrimSpace(params)
+		slot, known := slots[name]
+		if !ok || !known || strings.Trim(params, "0123456789;") != "" {
+			bad = append(bad, entry)
+			continue
+		}
+		*slot = sgr(params)
+	}
+	if len(bad) > 0 {
+		return fmt.Errorf("invalid theme.palette entries: %s", strings.Join(bad, ", "))
+	}
+	return nil
+}
+
+// FromConfig returns the palette for a [theme] section. "auto" honours
+// NO_COLOR (https://no-color.org) by picking mono; naming a theme or setting
+// palette overrides in the config wins over it.
+func FromConfig(c config.ThemeConfig) (Palette, error) {
+	name := c.Name
+	if name == "" || name == "auto" {
+		name = "default"
+		if os.Getenv("NO_COLOR") != "" {
+			name = "mono"
+		}
+	}
+	p, ok := builtin(name)
+	if !ok {
+		return Default, fmt.Errorf("unknown theme %q", c.Name)
+	}
+	return p, p.Override(c.Palette)
+}
+
+// Load returns the configured palette. Problems with the config fall back
+// to whatever could be applied, so the TUI always has something to draw with.
+func Load() Palette {
+	c, err := config.Load()
+	if err != nil {
+		c = config.Default()
+	}
+	p, _ := FromConfig(c.Theme)
+	return p
+}
@@ -0,0 +1,74 @@
+package theme
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nerveband/zpick/internal/config"
+)
+
+func TestFromConfigAuto(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	p, err := FromConfig(config.ThemeConfig{Name: "auto"})
+	if err != nil || p != Default {
+		t.Errorf("auto without NO_COLOR = %+v, %v; want Default", p, err)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	p, _ = FromConfig(config.ThemeConfig{Name: "auto"})
+	if mono, _ := builtin("mono"); p != mono {
+		t.Errorf("auto with NO_COLOR = %+v, want mono", p)
+	}
+
+	// Naming a theme is an explicit choice and wins over NO_COLOR.
+	p, _ = FromConfig(config.ThemeConfig{Name: "default"})
+	if p != Default {
+		t.Errorf("explicit default with NO_COLOR = %+v", p)
+	}
+}
+
+func TestMonoHasNoColor(t *testing.T) {
+	p, _ := builtin("mono")
+	v := reflect.ValueOf(p)
+	for i := 0; i < v.NumField(); i++ {
+		code := v.Field(i).String()
+		// SGR 30-37 and 90-97 are foreground colors.
+		for _, param := range strings.Split(strings.Trim(code, "\033[m"), ";") {
+			if len(param) == 2 && (param[0] == '3' || param[0] == '9') {
+				t.Errorf("mono %s = %q sets a color", v.Type().Field(i).Name, code)
+			}
+		}
+	}
+}
+
+func TestBuiltinsMatchConfigNames(t *testing.T) {
+	for _, name := range config.ThemeNames[1:] {
+		if _, ok := builtin(name); !ok {
+			t.Errorf("config.ThemeNames lists %q but there's no such theme", name)
+		}
+	}
+}
+
+func TestOverride(t *testing.T) {
+	p := Default
+	err := p.Override([]string{"bold_white=1;34", "dim=", "nope=1", "red=bright"})
+	if p.BoldWhite != "\033[1;34m" {
+		t.Errorf("bold_white = %q", p.BoldWhite)
+	}
+	if p.Dim != "" {
+		t.Errorf("dim = %q, want plain", p.Dim)
+	}
+	if p.Red != Default.Red {
+		t.Errorf("invalid red override applied: %q", p.Red)
+	}
+	if err == nil || !strings.Contains(err.Error(), "nope=1") || !strings.Contains(err.Error(), "red=bright") {
+		t.Errorf("err = %v, want both bad entries reported", err)
+	}
+}
+
+func TestFromConfigUnknownName(t *testing.T) {
+	if _, err := FromConfig(config.ThemeConfig{Name: "solarized"}); err == nil {
+		t.Error("unknown theme should be an error")
+	}
+}